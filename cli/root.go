@@ -24,7 +24,7 @@ var (
 
 func Execute() {
   if err := RootCmd.Execute(); err != nil {
-    log.Fatal(event.Error(event.ErrUnknown, err.Error()))
+    log.Fatal(event.Wrap(event.ErrUnknown, err))
     os.Exit(1)
   }
 }
@@ -32,4 +32,7 @@ func Execute() {
 func init() {
   RootCmd.AddCommand(cmd.VersionCmd)
   RootCmd.AddCommand(cmd.EventCmd)
+  RootCmd.AddCommand(cmd.ConfigCmd)
+  RootCmd.AddCommand(cmd.CertCmd)
+  RootCmd.AddCommand(cmd.SchemaCmd)
 }
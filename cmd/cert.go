@@ -0,0 +1,292 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cmd
+
+import (
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	event "github.com/anselmes/ce-go-template/event"
+	"github.com/spf13/cobra"
+)
+
+var (
+  certConfig string
+  certDir string
+  force bool
+
+  rootCert string
+  rootKey string
+  signerCert string
+  signerKey string
+  signerBundle string
+
+  certName string
+  certProfile string
+  commonName string
+  hosts []string
+  clientAuthUsage bool
+
+  trustFile string
+  warn time.Duration
+)
+
+var CertCmd = &cobra.Command{
+  Use:   "cert",
+  Aliases: []string{"certs"},
+  Short: "Manage the certificate authority and TLS certificates",
+  Long:  `
+  Create a root and intermediate CA and issue TLS certificates from the
+  profiles in cert.yaml, using the same file layout as cfssljson, then
+  inspect bundles and check them for upcoming expiry.
+  `,
+}
+
+var InitCACmd = &cobra.Command{
+  Use:   "init-ca",
+  Short: "Create the root CA (ca.pem, ca-key.pem)",
+  Run: func(cmd *cobra.Command, args []string) {
+    profiles, profile := loadProfile("ca")
+    protect("ca")
+
+    root, err := event.InitCA(profiles.CA, profile)
+    if err != nil {
+      log.Fatalln(err)
+    }
+    write(root, "ca")
+  },
+}
+
+var IntermediateCmd = &cobra.Command{
+  Use:   "intermediate",
+  Short: "Create the intermediate CA (intermediate.pem, intermediate-key.pem, ca-bundle.pem)",
+  Run: func(cmd *cobra.Command, args []string) {
+    profiles, profile := loadProfile("ca")
+    protect("intermediate")
+
+    root, err := event.LoadKeyPair(rootCert, rootKey)
+    if err != nil {
+      log.Fatalln(err)
+    }
+
+    intermediate, err := event.Sign(profiles.Intermediate, profile, root)
+    if err != nil {
+      log.Fatalln(err)
+    }
+    write(intermediate, "intermediate")
+    bundle("ca-bundle.pem", filepath.Join(certDir, "intermediate.pem"), rootCert)
+  },
+}
+
+var IssueCertCmd = &cobra.Command{
+  Use:   "issue",
+  Short: "Issue a TLS certificate (tls.pem, tls-key.pem, tls-bundle.pem)",
+  Run: func(cmd *cobra.Command, args []string) {
+    profiles, profile := loadProfile(certProfile)
+
+    request := profiles.TLS
+    if cmd.Flags().Changed("cn") {
+      request.CN = commonName
+    }
+    if cmd.Flags().Changed("host") {
+      request.Hosts = hosts
+    }
+    if clientAuthUsage {
+      profile.Usages = append(append([]string{}, profile.Usages...), "client auth")
+    }
+
+    signer, err := event.LoadKeyPair(signerCert, signerKey)
+    if err != nil {
+      log.Fatalln(err)
+    }
+
+    issued, err := event.Sign(request, profile, signer)
+    if err != nil {
+      log.Fatalln(err)
+    }
+    write(issued, certName)
+    bundle(certName+"-bundle.pem", filepath.Join(certDir, certName+".pem"), signerBundle)
+  },
+}
+
+var InspectCertCmd = &cobra.Command{
+  Use:   "inspect FILE",
+  Short: "Print the certificates of a bundle and verify the chain",
+  Args:  cobra.ExactArgs(1),
+  Run: func(cmd *cobra.Command, args []string) {
+    certs, err := event.ReadCertificates(args[0])
+    if err != nil {
+      log.Fatalln(err)
+    }
+
+    now := time.Now()
+    for i, cert := range certs {
+      fmt.Printf("[%d] %s\n", i, event.Role(cert))
+      fmt.Printf("  subject:   %s\n", cert.Subject)
+      fmt.Printf("  issuer:    %s\n", cert.Issuer)
+      fmt.Printf("  serial:    %X\n", cert.SerialNumber)
+      fmt.Printf("  sans:      %s\n", strings.Join(event.SANs(cert), ", "))
+      fmt.Printf("  usages:    %s\n", strings.Join(event.Usages(cert), ", "))
+      fmt.Printf("  validity:  %s to %s\n", cert.NotBefore.Format(time.RFC3339), cert.NotAfter.Format(time.RFC3339))
+      fmt.Printf("  expires:   in %.1f days\n", event.DaysUntilExpiry(cert, now))
+    }
+
+    failed := false
+    if err := event.CheckOrder(certs); err != nil {
+      fmt.Printf("chain order: %v\n", err)
+      failed = true
+    } else {
+      fmt.Println("chain order: ok")
+    }
+    if err := event.VerifyBundle(certs, loadTrust(), now); err != nil {
+      fmt.Printf("chain verification: %v\n", err)
+      failed = true
+    } else {
+      fmt.Println("chain verification: ok")
+    }
+
+    if failed {
+      os.Exit(int(event.ErrTlsConfig))
+    }
+  },
+}
+
+var CheckCertCmd = &cobra.Command{
+  Use:   "check [FILE...]",
+  Short: "Fail when a certificate is invalid or expires within --warn",
+  Long:  `
+  Verify each bundle (tls-bundle.pem by default) and exit non-zero when its
+  chain does not verify or any certificate in it expires within --warn.
+  `,
+  Run: func(cmd *cobra.Command, args []string) {
+    if len(args) == 0 {
+      args = []string{"tls-bundle.pem"}
+    }
+
+    now := time.Now()
+    roots := loadTrust()
+    failed := false
+    for _, file := range args {
+      certs, err := event.ReadCertificates(file)
+      if err != nil {
+        log.Println(err)
+        failed = true
+        continue
+      }
+
+      if err := event.VerifyBundle(certs, roots, now); err != nil {
+        log.Printf("%s: %v", file, err)
+        failed = true
+      }
+
+      for _, cert := range certs {
+        days := event.DaysUntilExpiry(cert, now)
+        switch {
+        case days <= 0:
+          log.Printf("%s: %s expired on %s", file, cert.Subject, cert.NotAfter.Format(time.RFC3339))
+          failed = true
+        case cert.NotAfter.Before(now.Add(warn)):
+          log.Printf("%s: %s expires in %.1f days, on %s", file, cert.Subject, days, cert.NotAfter.Format(time.RFC3339))
+          failed = true
+        default:
+          log.Printf("%s: %s valid for %.1f days", file, cert.Subject, days)
+        }
+      }
+    }
+
+    if failed {
+      os.Exit(int(event.ErrTlsConfig))
+    }
+  },
+}
+
+// loadTrust reads the --ca roots, nil meaning the bundle's own root or the
+// system roots are trusted.
+func loadTrust() *x509.CertPool {
+  if trustFile == "" {
+    return nil
+  }
+
+  certs, err := event.ReadCertificates(trustFile)
+  if err != nil {
+    log.Fatalln(err)
+  }
+  pool := x509.NewCertPool()
+  for _, cert := range certs {
+    pool.AddCert(cert)
+  }
+  return pool
+}
+
+// loadProfile reads cert.yaml and the named signing profile.
+func loadProfile(name string) (*event.CertConfig, event.SigningProfile) {
+  profiles, err := event.LoadCertConfig(certConfig)
+  if err != nil {
+    log.Fatalln(err)
+  }
+
+  profile, err := profiles.Profile(name)
+  if err != nil {
+    log.Fatalln(err)
+  }
+  return profiles, profile
+}
+
+// protect refuses to replace an existing CA key unless --force is given.
+func protect(name string) {
+  file := filepath.Join(certDir, name+"-key.pem")
+  if _, err := os.Stat(file); err == nil && !force {
+    log.Fatalln(event.Error(event.ErrTlsConfig, fmt.Sprintf("%s already exists, use --force to replace it", file)))
+  }
+}
+
+func write(pair *event.KeyPair, name string) {
+  if err := pair.Write(certDir, name); err != nil {
+    log.Fatalln(err)
+  }
+  log.Printf("Wrote %s.pem and %s-key.pem for %s, serial %X, expires %s", name, name, pair.Certificate.Subject, pair.Certificate.SerialNumber, pair.Certificate.NotAfter.Format(time.RFC3339))
+}
+
+func bundle(name string, parts ...string) {
+  if err := event.WriteBundle(filepath.Join(certDir, name), parts...); err != nil {
+    log.Fatalln(err)
+  }
+  log.Printf("Wrote %s", name)
+}
+
+func init() {
+  CertCmd.PersistentFlags().StringVar(&certConfig, "config", "cert.yaml", "Certificate profiles and requests")
+  CertCmd.PersistentFlags().StringVar(&certDir, "dir", ".", "Directory the certificates and keys are written to")
+
+  InitCACmd.Flags().BoolVar(&force, "force", false, "Replace an existing root CA")
+
+  IntermediateCmd.Flags().BoolVar(&force, "force", false, "Replace an existing intermediate CA")
+  IntermediateCmd.Flags().StringVar(&rootCert, "ca", "ca.pem", "Root CA certificate")
+  IntermediateCmd.Flags().StringVar(&rootKey, "ca-key", "ca-key.pem", "Root CA key")
+
+  IssueCertCmd.Flags().StringVar(&signerCert, "ca", "intermediate.pem", "Signing CA certificate")
+  IssueCertCmd.Flags().StringVar(&signerKey, "ca-key", "intermediate-key.pem", "Signing CA key")
+  IssueCertCmd.Flags().StringVar(&signerBundle, "ca-bundle", "ca-bundle.pem", "CA chain appended to the certificate bundle")
+  IssueCertCmd.Flags().StringVar(&certName, "name", "tls", "Base name of the files written (<name>.pem, <name>-key.pem, <name>-bundle.pem)")
+  IssueCertCmd.Flags().StringVar(&certProfile, "profile", "tls", "Signing profile from cert.yaml")
+  IssueCertCmd.Flags().StringVar(&commonName, "cn", "", "Common name (defaults to the tls request in cert.yaml)")
+  IssueCertCmd.Flags().StringSliceVar(&hosts, "host", nil, "Host names, IPs, URIs or emails for the SANs (defaults to the tls request hosts)")
+  IssueCertCmd.Flags().BoolVar(&clientAuthUsage, "client", false, "Also allow the certificate to be used for client authentication")
+
+  InspectCertCmd.Flags().StringVar(&trustFile, "ca", "", "CA bundle to verify against (defaults to the root in the bundle, then the system roots)")
+
+  CheckCertCmd.Flags().StringVar(&trustFile, "ca", "", "CA bundle to verify against (defaults to the root in the bundle, then the system roots)")
+  CheckCertCmd.Flags().DurationVar(&warn, "warn", 720*time.Hour, "Fail when a certificate expires within this duration")
+
+  CertCmd.AddCommand(InitCACmd)
+  CertCmd.AddCommand(IntermediateCmd)
+  CertCmd.AddCommand(IssueCertCmd)
+  CertCmd.AddCommand(InspectCertCmd)
+  CertCmd.AddCommand(CheckCertCmd)
+}
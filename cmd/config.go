@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	event "github.com/anselmes/ce-go-template/event"
+	"github.com/spf13/cobra"
+)
+
+var ConfigCmd = &cobra.Command{
+  Use:   "config",
+  Aliases: []string{"cfg"},
+  Short: "Manage cecli contexts",
+  Long:  `
+  Manage the named contexts stored in the cecli config file
+  (~/.config/cecli/config.yaml, or $CECLI_CONFIG).
+
+  Settings are resolved as flag > CE_* environment variable > context > default.
+  `,
+}
+
+var UseContextCmd = &cobra.Command{
+  Use:   "use-context NAME",
+  Short: "Set the current context",
+  Args:  cobra.ExactArgs(1),
+  Run: func(cmd *cobra.Command, args []string) {
+    path := event.DefaultConfigPath()
+    file := loadConfigFile(path)
+
+    if err := file.UseContext(args[0]); err != nil {
+      log.Fatalln(err)
+    }
+    if err := file.Save(path); err != nil {
+      log.Fatalln(err)
+    }
+    fmt.Printf("Switched to context %q.\n", args[0])
+  },
+}
+
+var GetContextsCmd = &cobra.Command{
+  Use:   "get-contexts",
+  Short: "List the contexts in the config file",
+  Run: func(cmd *cobra.Command, args []string) {
+    file := loadConfigFile(event.DefaultConfigPath())
+
+    writer := tabwriter.NewWriter(os.Stdout, 0, 4, 3, ' ', 0)
+    fmt.Fprintln(writer, "CURRENT\tNAME\tTARGET\tTRANSPORT\tSOURCE\tTYPE")
+    for _, named := range file.Contexts {
+      current := ""
+      if named.Name == file.CurrentContext {
+        current = "*"
+      }
+      fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", current, named.Name, named.Context.Target(), event.ValueOf(named.Context.Transport), event.ValueOf(named.Context.Source), event.ValueOf(named.Context.Type))
+    }
+    _ = writer.Flush()
+  },
+}
+
+var SetConfigCmd = &cobra.Command{
+  Use:   "set KEY VALUE",
+  Short: "Set a key of a context, creating the context if needed",
+  Long:  fmt.Sprintf(`
+  Set a key of the current context, or of the one given with --context.
+
+  Keys: %v
+  `, event.ContextKeys),
+  Args:  cobra.ExactArgs(2),
+  Run: func(cmd *cobra.Command, args []string) {
+    path := event.DefaultConfigPath()
+    file := loadConfigFile(path)
+
+    name := contextName
+    if name == "" {
+      name = file.CurrentContext
+    }
+
+    if err := file.Set(name, args[0], args[1]); err != nil {
+      log.Fatalln(err)
+    }
+    if file.CurrentContext == "" {
+      file.CurrentContext = name
+    }
+    if err := file.Save(path); err != nil {
+      log.Fatalln(err)
+    }
+  },
+}
+
+func loadConfigFile(path string) *event.ConfigFile {
+  file, err := event.LoadConfigFile(path)
+  if err != nil {
+    log.Fatalln(err)
+  }
+  return file
+}
+
+func init() {
+  SetConfigCmd.Flags().StringVar(&contextName, "context", "", "Context to change (defaults to the current context)")
+
+  ConfigCmd.AddCommand(UseContextCmd)
+  ConfigCmd.AddCommand(GetContextsCmd)
+  ConfigCmd.AddCommand(SetConfigCmd)
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	event "github.com/anselmes/ce-go-template/event"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/spf13/cobra"
+)
+
+var (
+  convertFrom string
+  convertTo string
+  curl bool
+)
+
+var ConvertEventCmd = &cobra.Command{
+  Use:   "convert [file...]",
+  Short: "Convert CloudEvents between encodings",
+  Long:  `
+  Convert CloudEvents read from files or stdin (-) into another encoding:
+
+    structured-json  one structured JSON event per line
+    binary-http      raw HTTP requests in binary mode, headers and body
+    batch            a JSON batch (application/cloudevents-batch+json)
+    protobuf         application/cloudevents+protobuf, length delimited for several events
+    avro             an Avro object container file in the CloudEvents Avro format
+
+  HTTP requests target the configured address and port. With --curl,
+  binary-http and batch are printed as curl commands instead.
+  `,
+  Run: func(cmd *cobra.Command, args []string) {
+    if err := loadConfig(cmd); err != nil {
+      log.Fatalln(err)
+    }
+    if curl && convertTo != event.EncodingBinaryHTTP && convertTo != event.EncodingBatch {
+      log.Fatalln(event.Error(event.ErrInvalidFormat, "--curl only applies to binary-http and batch"))
+    }
+
+    if len(args) == 0 {
+      args = []string{"-"}
+    }
+
+    var events []cloudevents.Event
+    for _, file := range args {
+      content, _, err := event.ReadPayloadFile(file)
+      if err != nil {
+        log.Fatalln(err)
+      }
+      found, err := event.ReadEncodedEvents(content, convertFrom)
+      if err != nil {
+        log.Fatalln(event.Wrap(event.ErrInvalidFormat, err, fmt.Sprintf("%s: %s", file, event.Message(err))))
+      }
+      events = append(events, found...)
+    }
+
+    if err := convert(events); err != nil {
+      log.Fatalln(err)
+    }
+  },
+}
+
+// convert writes the events to stdout in the requested encoding.
+func convert(events []cloudevents.Event) error {
+  ctx := context.Background()
+  target := config.Url().String()
+
+  switch strings.ToLower(convertTo) {
+  case event.EncodingStructuredJSON:
+    return event.EncodeJSON(os.Stdout, events)
+  case event.EncodingProtobuf:
+    return event.EncodeProtobuf(os.Stdout, events)
+  case event.EncodingAvro:
+    return event.EncodeAvro(os.Stdout, events)
+  case event.EncodingBatch:
+    if !curl {
+      return event.EncodeBatch(os.Stdout, events)
+    }
+    req, err := event.BatchRequest(ctx, target, events)
+    if err != nil {
+      return err
+    }
+    return printRequest(req)
+  case event.EncodingBinaryHTTP:
+    for index, ce := range events {
+      req, err := event.BinaryRequest(ctx, target, ce)
+      if err != nil {
+        return err
+      }
+      if index > 0 {
+        fmt.Println()
+      }
+      if err := printRequest(req); err != nil {
+        return err
+      }
+    }
+    return nil
+  default:
+    return event.Error(event.ErrInvalidFormat, fmt.Sprintf("cannot convert to %q (structured-json, binary-http, batch, protobuf, avro)", convertTo))
+  }
+}
+
+// printRequest prints a request as sent on the wire, or as a curl command.
+func printRequest(req *http.Request) error {
+  if curl {
+    var options []string
+    if config.CA != "" && req.URL.Scheme == "https" {
+      options = append(options, "  --cacert "+event.ShellQuote(config.CA))
+    }
+    command, err := event.CurlCommand(req, options...)
+    if err != nil {
+      return err
+    }
+    fmt.Println(command)
+    return nil
+  }
+
+  dump, err := event.DumpRequest(req)
+  if err != nil {
+    return err
+  }
+  _, err = os.Stdout.Write(dump)
+  return err
+}
+
+func init() {
+  ConvertEventCmd.Flags().StringVar(&convertFrom, "from", event.EncodingStructuredJSON, "Encoding of the input (structured-json, batch, binary-http)")
+  ConvertEventCmd.Flags().StringVar(&convertTo, "to", "", "Encoding of the output (structured-json, binary-http, batch, protobuf, avro)")
+  ConvertEventCmd.Flags().BoolVar(&curl, "curl", false, "Print HTTP requests as curl commands")
+  _ = ConvertEventCmd.MarkFlagRequired("to")
+}
@@ -5,6 +5,12 @@ package cmd
 
 import (
 	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	api "github.com/anselmes/ce-go-template/api/v1"
 	event "github.com/anselmes/ce-go-template/event"
@@ -15,19 +21,57 @@ import (
 var (
   address string
   port int
+  socket string
   endpoint string
 
   cert string
   key string
+  ca string
+  clientCert string
+  clientKey string
+  serverCert string
+  serverKey string
+  clientAuth string
   insecure bool
   verify bool
 
   client cloudevents.Client
   config *event.CloudEventConfig
   manager *event.CloudEventManager
+  options = &event.CloudEventOptions{}
   ctx context.Context
 
   data string
+
+  mode string
+  format string
+  shutdownTimeout time.Duration
+
+  transport string
+  brokers []string
+  topic string
+  group string
+
+  subject string
+  legacySubject string
+  queue string
+  stream string
+  durable string
+
+  qos int
+  retain bool
+  mqttVersion int
+
+  node string
+  linkName string
+  credit int
+
+  origins []string
+
+  contextName string
+  unknownType string
+  schemaDir string
+  fetchSchemas bool
 )
 
 // MARK: - Command
@@ -42,41 +86,188 @@ var EventCmd = &cobra.Command{
 }
 
 func init() {
+  EventCmd.PersistentFlags().StringVar(&contextName, "context", "", "Context from the config file to use (defaults to the current context)")
   EventCmd.PersistentFlags().StringVar(&address, "address", "localhost", "The address to listen on")
   EventCmd.PersistentFlags().IntVar(&port, "port", 8080, "The port to listen on")
+  EventCmd.PersistentFlags().StringVar(&socket, "socket", "", "Unix domain socket to listen on or send to instead of a TCP port")
 
   EventCmd.PersistentFlags().BoolVarP(&insecure, "insecure", "k", false, "Disable TLS verification")
   EventCmd.PersistentFlags().BoolVar(&verify, "verify", true, "Enable TLS verification")
   EventCmd.PersistentFlags().StringVar(&cert, "cert", "tls-bundle.pem", "Path to TLS certificate file")
   EventCmd.PersistentFlags().StringVar(&key, "key", "tls-key.pem", "Path to TLS key file")
+  EventCmd.PersistentFlags().StringVar(&ca, "ca", "", "Path to the CA bundle used to verify peers (defaults to --cert)")
+  EventCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "", "Path to the client certificate presented when sending (defaults to --cert)")
+  EventCmd.PersistentFlags().StringVar(&clientKey, "client-key", "", "Path to the client key (defaults to --key)")
+  EventCmd.PersistentFlags().StringVar(&serverCert, "server-cert", "", "Path to the server certificate used when listening (defaults to --cert)")
+  EventCmd.PersistentFlags().StringVar(&serverKey, "server-key", "", "Path to the server key (defaults to --key)")
+  EventCmd.PersistentFlags().StringVar(&clientAuth, "client-auth", event.ClientAuthNone, "Client certificate policy when listening (require, verify-if-given, none)")
+
+  EventCmd.PersistentFlags().StringVarP(&data, "data", "d", "", "CloudEvent data payload to send, sent as-is (@file or @- reads a file or stdin)")
+
+  EventCmd.PersistentFlags().StringVar(&unknownType, "unknown-type", event.UnknownTypePassThrough, "Handling of event types missing from the schema registry (reject, pass-through, warn)")
 
-  EventCmd.PersistentFlags().StringVarP(&data, "data", "d", "", "CloudEvent data payload to send")
+  EventCmd.PersistentFlags().StringVar(&schemaDir, "schema", "", "Directory of JSON Schemas to validate event data against")
+  EventCmd.PersistentFlags().BoolVar(&fetchSchemas, "fetch-schemas", false, "Fetch JSON Schemas from http(s) dataschema URLs")
+
+  EventCmd.PersistentFlags().StringVar(&transport, "transport", event.ProtocolHTTP, "Transport used to exchange CloudEvents (http, kafka, nats, jetstream, mqtt, amqp, grpc, websocket)")
+  EventCmd.PersistentFlags().StringSliceVar(&brokers, "brokers", nil, "Kafka brokers (defaults to address:port)")
+  EventCmd.PersistentFlags().StringVar(&topic, "topic", "cloudevents", "Kafka or MQTT topic to send to or consume from")
+  EventCmd.PersistentFlags().StringVar(&group, "group", "cecli", "Kafka consumer group")
+  EventCmd.PersistentFlags().StringVar(&subject, "nats-subject", "cloudevents", "NATS subject to publish to or subscribe on")
+  EventCmd.PersistentFlags().StringVar(&legacySubject, "subject", "cloudevents", "NATS subject to publish to or subscribe on")
+  _ = EventCmd.PersistentFlags().MarkDeprecated("subject", "use --nats-subject")
+  EventCmd.PersistentFlags().StringVar(&queue, "queue", "", "NATS queue group to join when listening")
+  EventCmd.PersistentFlags().StringVar(&stream, "stream", "cloudevents", "JetStream stream holding the subject")
+  EventCmd.PersistentFlags().StringVar(&durable, "durable", "", "JetStream durable consumer name")
+  EventCmd.PersistentFlags().IntVar(&qos, "qos", 0, "MQTT quality of service level (0, 1, 2)")
+  EventCmd.PersistentFlags().BoolVar(&retain, "retain", false, "Publish MQTT messages with the retain flag")
+  EventCmd.PersistentFlags().IntVar(&mqttVersion, "mqtt-version", 5, "MQTT protocol version (3 or 5)")
+  EventCmd.PersistentFlags().StringVar(&node, "node", "cloudevents", "AMQP node address (queue or topic)")
+  EventCmd.PersistentFlags().StringVar(&linkName, "link-name", "", "AMQP link name")
+  EventCmd.PersistentFlags().IntVar(&credit, "credit", 10, "AMQP receiver link credit")
+  EventCmd.PersistentFlags().StringSliceVar(&origins, "origin", nil, "Origins allowed to open a WebSocket (* for any, default same origin)")
 
   // MARK: - Sub Command
 
   EventCmd.AddCommand(EventWebhookCmd)
+  EventCmd.AddCommand(GrpcServeCmd)
   EventCmd.AddCommand(ListenEventCmd)
   EventCmd.AddCommand(SendEventCmd)
+  EventCmd.AddCommand(ValidateEventCmd)
+  EventCmd.AddCommand(ConvertEventCmd)
 }
 
-func initializeClient() error {
-  manager = event.NewCloudEventManager(&api.Data{}, nil)
-  config = &event.CloudEventConfig{
-    Address: address,
-    Port: port,
-    Certificate: cert,
-    CertificateKey: key,
-    Insecure: insecure,
-    SkipVerify: !verify,
+// loadConfig resolves the configuration with flags taking precedence over
+// CE_* variables, which take precedence over the selected context.
+func loadConfig(cmd *cobra.Command) error {
+  file, err := event.LoadConfigFile(event.DefaultConfigPath())
+  if err != nil {
+    return err
+  }
+
+  selected, err := file.Context(contextName)
+  if err != nil {
+    return err
+  }
+
+  if config, err = event.LoadConfig(selected); err != nil {
+    return err
+  }
+
+  override(cmd, "address", &config.Address, address)
+  override(cmd, "port", &config.Port, port)
+  override(cmd, "socket", &config.Socket, socket)
+  override(cmd, "cert", &config.Certificate, cert)
+  override(cmd, "key", &config.CertificateKey, key)
+  override(cmd, "ca", &config.CA, ca)
+  override(cmd, "client-cert", &config.ClientCertificate, clientCert)
+  override(cmd, "client-key", &config.ClientKey, clientKey)
+  override(cmd, "server-cert", &config.ServerCertificate, serverCert)
+  override(cmd, "server-key", &config.ServerKey, serverKey)
+  override(cmd, "client-auth", &config.ClientAuth, clientAuth)
+  override(cmd, "insecure", &config.Insecure, insecure)
+  override(cmd, "verify", &config.SkipVerify, !verify)
+  override(cmd, "transport", &config.Protocol, transport)
+  override(cmd, "mode", &config.Mode, mode)
+  override(cmd, "format", &config.Format, format)
+  override(cmd, "shutdown-timeout", &config.ShutdownTimeout, shutdownTimeout)
+  override(cmd, "source", &config.Source, eventSource)
+  override(cmd, "type", &config.Type, eventType)
+  override(cmd, "unknown-type", &config.UnknownType, unknownType)
+  override(cmd, "schema", &config.SchemaDir, schemaDir)
+  override(cmd, "fetch-schemas", &config.FetchSchemas, fetchSchemas)
+
+  override(cmd, "retry", &config.Retry.Enable, retry)
+  override(cmd, "attempts", &config.Retry.Attempts, attempt)
+  override(cmd, "timeout", &config.Retry.Delay, time.Duration(timeout)*time.Millisecond)
+  override(cmd, "backoff", &config.Retry.Backoff, backoff)
+  override(cmd, "jitter", &config.Retry.Jitter, jitter)
+  override(cmd, "max-delay", &config.Retry.MaxDelay, maxDelay)
+  override(cmd, "max-elapsed", &config.Retry.MaxElapsed, maxElapsed)
+
+  override(cmd, "brokers", &config.Kafka.Brokers, brokers)
+  override(cmd, "topic", &config.Kafka.Topic, topic)
+  override(cmd, "group", &config.Kafka.Group, group)
+  override(cmd, "nats-subject", &config.Nats.Subject, subject)
+  // --subject still chooses the NATS subject when --nats-subject is not
+  // given, on send only over NATS since it otherwise sets the event subject
+  if cmd.Flags().Changed("subject") && !cmd.Flags().Changed("nats-subject") {
+    if cmd.LocalNonPersistentFlags().Lookup("subject") == nil {
+      config.Nats.Subject = legacySubject
+    } else if isNats(config) {
+      config.Nats.Subject = eventSubject
+      eventSubject = ""
+    }
+  }
+  override(cmd, "queue", &config.Nats.Queue, queue)
+  override(cmd, "stream", &config.Nats.Stream, stream)
+  override(cmd, "durable", &config.Nats.Durable, durable)
+  override(cmd, "topic", &config.Mqtt.Topic, topic)
+  override(cmd, "qos", &config.Mqtt.QoS, qos)
+  override(cmd, "retain", &config.Mqtt.Retain, retain)
+  override(cmd, "mqtt-version", &config.Mqtt.Version, mqttVersion)
+  override(cmd, "node", &config.Amqp.Address, node)
+  override(cmd, "link-name", &config.Amqp.LinkName, linkName)
+  override(cmd, "credit", &config.Amqp.Credit, credit)
+  override(cmd, "origin", &config.WebSocket.Origins, origins)
+
+  return nil
+}
+
+// isNats reports whether events travel over NATS or JetStream.
+func isNats(config *event.CloudEventConfig) bool {
+  protocol := strings.ToLower(config.Protocol)
+  return protocol == event.ProtocolNATS || protocol == event.ProtocolJetStream
+}
+
+// override replaces a configured value with the flag value when the flag was
+// given on the command line.
+func override[T any](cmd *cobra.Command, name string, target *T, value T) {
+  if cmd.Flags().Changed(name) {
+    *target = value
+  }
+}
+
+// initializeManager creates the manager from the loaded config.
+func initializeManager() error {
+  options.Source = config.Source
+  options.Type = config.Type
+  manager = event.NewCloudEventManager(&api.Data{}, options)
+  if err := manager.SetUnknownType(config.UnknownType); err != nil {
+    return err
+  }
+  if config.SchemaDir != "" || config.FetchSchemas {
+    schemas, err := event.NewSchemaValidator(config.SchemaDir, config.FetchSchemas)
+    if err != nil {
+      return err
+    }
+    manager.SetSchemas(schemas)
   }
 
   endpoint = config.Url().String()
   ctx = cloudevents.ContextWithTarget(context.Background(), endpoint)
+  return nil
+}
 
+// initializeSender creates the client used to send. Only send needs one,
+// listeners receive through Listen, so they open no producer connection.
+func initializeSender() error {
   var err error
-  if client, err = config.Client(); err != nil {
-    return event.Error(event.ErrUnknown, err.Error())
+  client, err = config.Client(ctx)
+  return err
+}
+
+// closeSender closes the connection of the sending client, flushing
+// messages the transport still buffers.
+func closeSender() {
+  closing, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+  defer cancel()
+  if err := event.Close(closing, client); err != nil {
+    log.Printf("Failed to close the sender: %v", err)
   }
+}
 
-  return nil
+// signalContext derives a context that is cancelled on SIGINT or SIGTERM.
+func signalContext(parent context.Context) (context.Context, context.CancelFunc) {
+  return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
 }
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	event "github.com/anselmes/ce-go-template/event"
+)
+
+func TestLoadConfigPrefersFlagsThenEnvironmentThenContext(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "config.yaml")
+  t.Setenv("CECLI_CONFIG", path)
+
+  file := &event.ConfigFile{CurrentContext: "staging"}
+  for key, value := range map[string]string{"address": "events.example.com", "port": "9000", "retry.enable": "false", "retry.attempts": "0"} {
+    if err := file.Set("staging", key, value); err != nil {
+      t.Fatal(err)
+    }
+  }
+  if err := file.Save(path); err != nil {
+    t.Fatal(err)
+  }
+
+  for _, name := range []string{"CE_ADDRESS", "CE_RETRY_ATTEMPTS"} {
+    t.Setenv(name, "")
+    _ = os.Unsetenv(name)
+  }
+  t.Setenv("CE_PORT", "9100")
+  t.Setenv("CE_RETRY", "true")
+
+  if err := SendEventCmd.ParseFlags([]string{"--port", "9200", "--retry=false"}); err != nil {
+    t.Fatal(err)
+  }
+  if err := loadConfig(SendEventCmd); err != nil {
+    t.Fatal(err)
+  }
+
+  if config.Port != 9200 {
+    t.Errorf("port = %d, want 9200 from the flag over CE_PORT and the context", config.Port)
+  }
+  if config.Retry.Enable {
+    t.Error("retry enabled, want --retry=false over CE_RETRY")
+  }
+  if config.Retry.Attempts != 0 {
+    t.Errorf("attempts = %d, want 0 from the context over the default", config.Retry.Attempts)
+  }
+  if config.Address != "events.example.com" {
+    t.Errorf("address = %q, want events.example.com from the context", config.Address)
+  }
+}
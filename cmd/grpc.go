@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cmd
+
+import (
+	"log"
+	"time"
+
+	event "github.com/anselmes/ce-go-template/event"
+	"github.com/spf13/cobra"
+)
+
+var GrpcServeCmd = &cobra.Command{
+  Use:   "grpc-serve",
+  Short: "Serve the CloudEvent gRPC service",
+  Long:  `
+  Serve the gRPC EventService, accepting published CloudEvents and streaming
+  them to subscribers.
+  `,
+  Run: func(cmd *cobra.Command, args []string) {
+    if err := loadConfig(cmd); err != nil {
+      log.Fatalln(err)
+    }
+    config.Protocol = event.ProtocolGRPC
+
+    if err := initializeManager(); err != nil {
+      log.Fatalln(event.Wrap(event.ErrReceiveFailed, err))
+    }
+
+    ctx, stop := signalContext(ctx)
+    defer stop()
+
+    if err := manager.ListenGRPC(ctx, config, manager.Display); err != nil {
+      log.Fatalln(err)
+    }
+  },
+}
+
+func init() {
+  GrpcServeCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 10*time.Second, "Grace period to drain in-flight events on shutdown")
+}
@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"log"
+	"time"
 
 	event "github.com/anselmes/ce-go-template/event"
 	"github.com/spf13/cobra"
@@ -18,9 +19,35 @@ var ListenEventCmd = &cobra.Command{
   Listen CloudEvent from a specified target.
   `,
   Run: func(cmd *cobra.Command, args []string) {
-    if err := initializeClient(); err != nil {
-      log.Fatalln(event.Error(event.ErrReceiveFailed, err.Error()))
+    if err := loadConfig(cmd); err != nil {
+      log.Fatalln(err)
+    }
+
+    if err := initializeManager(); err != nil {
+      log.Fatalln(event.Wrap(event.ErrReceiveFailed, err))
+    }
+
+    // Only restrict structured events when a format was asked for
+    if cmd.Flags().Changed("format") {
+      eventFormat, err := event.ParseFormat(config.Format)
+      if err != nil {
+        log.Fatalln(err)
+      }
+      if err := manager.SetFormat(eventFormat); err != nil {
+        log.Fatalln(err)
+      }
+    }
+
+    ctx, stop := signalContext(ctx)
+    defer stop()
+
+    if err := manager.Listen(ctx, config, manager.Display); err != nil {
+      log.Fatalln(err)
     }
-    log.Fatal(manager.Listen(ctx, config, manager.Display))
   },
 }
+
+func init() {
+  ListenEventCmd.Flags().StringVar(&format, "format", event.FormatJSON, "Only accept structured events in this format (json, protobuf)")
+  ListenEventCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 10*time.Second, "Grace period to drain in-flight events on shutdown")
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	event "github.com/anselmes/ce-go-template/event"
+	"github.com/spf13/cobra"
+)
+
+var SchemaCmd = &cobra.Command{
+  Use:   "schema",
+  Short: "Inspect the event type registry",
+  Long:  `
+  Inspect the registry mapping CloudEvents types to the protobuf messages
+  their data is validated against when sending and decoded into when
+  listening.
+  `,
+}
+
+var ListSchemaCmd = &cobra.Command{
+  Use:   "list",
+  Aliases: []string{"ls"},
+  Short: "List the registered event types",
+  Run: func(cmd *cobra.Command, args []string) {
+    writer := tabwriter.NewWriter(os.Stdout, 0, 4, 3, ' ', 0)
+    fmt.Fprintln(writer, "TYPE\tMESSAGE\tDATASCHEMA")
+    for _, entry := range event.Types.Entries() {
+      fmt.Fprintf(writer, "%s\t%s\t%s\n", entry.Type, entry.Message, entry.DataSchema)
+    }
+    _ = writer.Flush()
+  },
+}
+
+func init() {
+  SchemaCmd.AddCommand(ListSchemaCmd)
+}
@@ -4,8 +4,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	event "github.com/anselmes/ce-go-template/event"
@@ -18,6 +21,33 @@ var (
   retry bool
   timeout int
   verbose bool
+
+  backoff string
+  jitter string
+  maxDelay time.Duration
+  maxElapsed time.Duration
+  retryStatus []int
+
+  attemptTimeout time.Duration
+  deadline time.Duration
+
+  batchFile string
+  batchSize int
+
+  partitionKey string
+
+  eventSource string
+  eventType string
+  eventID string
+  eventSubject string
+  eventTime string
+  dataSchema string
+  dataContentType string
+  specVersion string
+  extensions []string
+
+  dataFile string
+  protoMessage string
 )
 
 var SendEventCmd = &cobra.Command{
@@ -27,45 +57,243 @@ var SendEventCmd = &cobra.Command{
   Send a CloudEvent to a specified target.
   `,
   Run: func(cmd *cobra.Command, args []string) {
-    if err := initializeClient(); err != nil {
-      log.Fatalln(event.Error(event.ErrReceiveFailed, err.Error()))
+    if err := loadConfig(cmd); err != nil {
+      log.Fatalln(err)
+    }
+
+    // Batch files are always delivered using the batch content mode
+    if batchFile != "" {
+      config.Mode = "batch"
+    }
+
+    // Protobuf events only have a wire form in structured mode
+    if config.Format == event.FormatProtobuf && !cmd.Flags().Changed("mode") && os.Getenv("CE_MODE") == "" {
+      config.Mode = "structured"
+    }
+
+    eventFormat, err := event.ParseFormat(config.Format)
+    if err != nil {
+      log.Fatalln(err)
+    }
+
+    if err := parseAttributes(); err != nil {
+      log.Fatalln(err)
+    }
+
+    if err := initializeManager(); err != nil {
+      log.Fatalln(event.Wrap(event.ErrReceiveFailed, err))
+    }
+    if err := manager.SetFormat(eventFormat); err != nil {
+      log.Fatalln(err)
+    }
+
+    if err := applyPayload(); err != nil {
+      log.Fatalln(err)
+    }
+    if partitionKey != "" { manager.Event.SetExtension("partitionkey", partitionKey) }
+
+    if err := manager.Validate(); err != nil {
+      log.Fatalln(err)
     }
 
     if print {
-      json, err := manager.Json()
+      encoded, err := manager.Encode()
       if err != nil {
         log.Fatalf("failed to marshal CloudEvent, %v", err)
       }
-      fmt.Printf("%s\n", json)
+      if event.IsProtobuf(eventFormat) {
+        _, _ = os.Stdout.Write(encoded)
+        return
+      }
+      fmt.Printf("%s\n", encoded)
       return
     }
 
-    if data != "" { manager.FromJson([]byte(data)) }
+    // Tuning the backoff implies retries are wanted
+    for _, name := range []string{"backoff", "jitter", "max-delay", "max-elapsed", "retry-status"} {
+      config.Retry.Enable = config.Retry.Enable || cmd.Flags().Changed(name)
+    }
 
-    if retry {
-      log.Printf("Retry enabled: %d attempts with %d ms timeout", attempt, timeout)
-      manager.SetRetry(attempt)
-      manager.SetTimeout(time.Duration(timeout))
+    if config.Retry.Enable {
+      policy, err := config.Retry.Policy()
+      if err != nil {
+        log.Fatalln(err)
+      }
+      policy.RetryableStatus = retryStatus
+      log.Printf("Retry enabled: %d attempts with %s backoff from %s", policy.Attempts, config.Retry.Backoff, config.Retry.Delay)
+      manager.SetRetryPolicy(policy)
     } else {
       // Default to single attempt when retry is disabled
       manager.SetRetry(1)
-      manager.SetTimeout(time.Duration(1000))
+      manager.SetTimeout(time.Second)
+    }
+    manager.SetAttemptTimeout(attemptTimeout)
+
+    // Stop retrying on Ctrl-C, SIGTERM or once the deadline is reached
+    ctx, stop := signalContext(ctx)
+    defer stop()
+    if deadline > 0 {
+      var cancel context.CancelFunc
+      ctx, cancel = context.WithTimeout(ctx, deadline)
+      defer cancel()
     }
 
     log.Printf("Sending CloudEvent...")
 
+    if err := initializeSender(); err != nil {
+      log.Fatalln(err)
+    }
+
     if verbose {
       log.Println(manager.Event)
     }
 
-    manager.Send(ctx, client)
+    code := 0
+    if batchFile != "" {
+      code = sendBatch(ctx)
+    } else if report, err := manager.Send(ctx, client); err != nil {
+      log.Printf("CloudEvent %s after %d attempt(s): %v", report.Status, len(report.Attempts), err)
+      code = report.ExitCode()
+    }
+
+    closeSender()
+    if code != 0 {
+      os.Exit(code)
+    }
   },
 }
 
+// parseAttributes fills the event options from the attribute flags.
+func parseAttributes() error {
+  options.ID = eventID
+  options.Subject = eventSubject
+  options.DataSchema = dataSchema
+  options.DataContentType = dataContentType
+  options.SpecVersion = specVersion
+
+  if eventTime != "" {
+    parsed, err := time.Parse(time.RFC3339Nano, eventTime)
+    if err != nil {
+      return event.Error(event.ErrInvalidFormat, fmt.Sprintf("--time must be an RFC 3339 timestamp: %v", err))
+    }
+    options.Time = parsed
+  }
+
+  options.Extensions = map[string]string{}
+  for _, extension := range extensions {
+    name, value, ok := strings.Cut(extension, "=")
+    if !ok || name == "" {
+      return event.Error(event.ErrInvalidFormat, fmt.Sprintf("--ext %q must be key=value", extension))
+    }
+    options.Extensions[name] = value
+  }
+  return nil
+}
+
+// applyPayload attaches --data (@file and @- read a file or stdin) or
+// --data-file unchanged, or strictly decoded into --proto or the message
+// registered for the event type.
+func applyPayload() error {
+  if data != "" && dataFile != "" {
+    return event.Error(event.ErrInvalidFormat, "--data and --data-file cannot be combined")
+  }
+
+  name := protoMessage
+  if name == "" {
+    var err error
+    if name, err = manager.ExpectedMessage(manager.Event.Type()); err != nil {
+      return err
+    }
+  }
+
+  var payload []byte
+  var detected string
+  var err error
+  switch {
+  case dataFile != "":
+    payload, detected, err = event.ReadPayloadFile(dataFile)
+  case data != "":
+    payload, detected, err = event.ReadPayload(data)
+  default:
+    return nil
+  }
+  if err != nil {
+    return err
+  }
+
+  contentType := dataContentType
+  if contentType == "" {
+    contentType = detected
+  }
+
+  if name != "" {
+    return manager.DecodePayload(payload, contentType, name)
+  }
+  return manager.SetPayload(payload, contentType)
+}
+
+// sendBatch sends the events of the batch file, returning the exit code of
+// the first batch that was not delivered.
+func sendBatch(ctx context.Context) int {
+  reader := os.Stdin
+  if batchFile != "-" {
+    file, err := os.Open(batchFile)
+    if err != nil {
+      log.Fatalln(event.Wrap(event.ErrInvalidFormat, err))
+    }
+    defer func() { _ = file.Close() }()
+    reader = file
+  }
+
+  events, err := event.ReadEvents(reader)
+  if err != nil {
+    log.Fatalln(err)
+  }
+
+  batch, ok := client.(event.BatchSender)
+  if !ok {
+    log.Fatalln(event.Error(event.ErrInvalidFormat, "batch files require the batch content mode"))
+  }
+
+  reports, err := manager.SendBatch(ctx, batch, events, batchSize)
+  if err != nil {
+    log.Println(err)
+    for _, report := range reports {
+      if !report.Delivered() {
+        return report.ExitCode()
+      }
+    }
+  }
+  return 0
+}
+
 func init() {
+  SendEventCmd.Flags().StringVar(&mode, "mode", "binary", "Content mode for the outgoing event (binary, structured, batch)")
+  SendEventCmd.Flags().StringVar(&format, "format", event.FormatJSON, "Event format for structured mode and dry-run (json, protobuf)")
+  SendEventCmd.Flags().StringVar(&eventSource, "source", "", "Event source attribute (defaults to ce/uri)")
+  SendEventCmd.Flags().StringVar(&eventType, "type", "", "Event type attribute (defaults to ce.type)")
+  SendEventCmd.Flags().StringVar(&eventID, "id", "", "Event id attribute (defaults to a random UUID)")
+  SendEventCmd.Flags().StringVar(&eventSubject, "subject", "", "Event subject attribute, or the NATS subject over nats and jetstream when --nats-subject is not given")
+  SendEventCmd.Flags().StringVar(&eventTime, "time", "", "Event time attribute as an RFC 3339 timestamp (defaults to now)")
+  SendEventCmd.Flags().StringVar(&dataSchema, "dataschema", "", "Event dataschema attribute")
+  SendEventCmd.Flags().StringVar(&dataContentType, "datacontenttype", "", "Event datacontenttype attribute (defaults to the content type of the data)")
+  SendEventCmd.Flags().StringVar(&specVersion, "specversion", "1.0", "CloudEvents spec version (0.3, 1.0)")
+  SendEventCmd.Flags().StringArrayVar(&extensions, "ext", nil, "Extension attribute as key=value, repeatable")
+  SendEventCmd.Flags().StringVar(&dataFile, "data-file", "", "Read the payload from a file (- for stdin) instead of --data")
+  SendEventCmd.Flags().StringVar(&protoMessage, "proto", "", "Strictly decode the payload into this registered proto message (e.g. api.Data)")
+  SendEventCmd.Flags().StringVar(&partitionKey, "partition-key", "", "Set the partitionkey extension, used as the Kafka message key")
+  SendEventCmd.Flags().StringVar(&batchFile, "batch-file", "", "Send newline delimited CloudEvents from a file (- for stdin) as batches")
+  SendEventCmd.Flags().IntVar(&batchSize, "batch-size", 100, "Maximum number of events per batch request")
   SendEventCmd.Flags().BoolVar(&retry, "retry", false, "Enable retry mechanism")
   SendEventCmd.Flags().IntVar(&attempt, "attempts", 3, "Number of retry attempts")
-  SendEventCmd.Flags().IntVar(&timeout, "timeout", 1000, "Timeout between retry attempts in milliseconds")
+  SendEventCmd.Flags().IntVar(&timeout, "timeout", 1000, "Base delay between retry attempts in milliseconds")
+  SendEventCmd.Flags().StringVar(&backoff, "backoff", "constant", "Backoff strategy between retries (constant, linear, exponential)")
+  SendEventCmd.Flags().StringVar(&jitter, "jitter", "none", "Jitter applied to exponential backoff (none, full, decorrelated)")
+  SendEventCmd.Flags().DurationVar(&maxDelay, "max-delay", 30*time.Second, "Upper bound for a single backoff delay")
+  SendEventCmd.Flags().DurationVar(&maxElapsed, "max-elapsed", 0, "Total time budget for all attempts (0 for unlimited)")
+  SendEventCmd.Flags().IntSliceVar(&retryStatus, "retry-status", event.DefaultRetryableStatus, "HTTP status codes that are retried")
+  SendEventCmd.Flags().DurationVar(&attemptTimeout, "attempt-timeout", 0, "Timeout for each individual send attempt (0 for none)")
+  SendEventCmd.Flags().DurationVar(&deadline, "deadline", 0, "Overall deadline for sending, including retries (0 for none)")
   SendEventCmd.Flags().BoolVar(&print, "dry-run", false, "Print the CloudEvent JSON without sending it")
   SendEventCmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose output")
 }
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	event "github.com/anselmes/ce-go-template/event"
+	"github.com/spf13/cobra"
+)
+
+var (
+  output string
+  strict bool
+)
+
+var ValidateEventCmd = &cobra.Command{
+  Use:   "validate [file...]",
+  Short: "Validate CloudEvents against the spec and JSON Schemas",
+  Long:  `
+  Validate CloudEvents read from files or stdin (-) against CloudEvents 1.0:
+  required attributes, attribute naming, extension value types, time format,
+  URI-reference source and datacontenttype vs data consistency. Files may hold
+  a structured JSON event, a JSON batch, newline delimited events or an HTTP
+  request dump in any content mode.
+
+  With --schema, event data is also checked against its JSON Schema. Nothing
+  is sent, and the command exits non-zero when any event has errors, or
+  warnings with --strict.
+  `,
+  Run: func(cmd *cobra.Command, args []string) {
+    if err := loadConfig(cmd); err != nil {
+      log.Fatalln(err)
+    }
+    if output != "text" && output != "json" {
+      log.Fatalln(event.Error(event.ErrInvalidFormat, fmt.Sprintf("unsupported output %q", output)))
+    }
+
+    var schemas *event.SchemaValidator
+    if config.SchemaDir != "" || config.FetchSchemas {
+      var err error
+      if schemas, err = event.NewSchemaValidator(config.SchemaDir, config.FetchSchemas); err != nil {
+        log.Fatalln(err)
+      }
+    }
+
+    if len(args) == 0 {
+      args = []string{"-"}
+    }
+
+    reports := []event.ConformanceReport{}
+    for _, file := range args {
+      found, err := validateFile(file, schemas)
+      if err != nil {
+        log.Fatalln(err)
+      }
+      reports = append(reports, found...)
+    }
+
+    valid := true
+    for _, report := range reports {
+      valid = valid && report.Valid
+    }
+
+    if output == "json" {
+      encoder := json.NewEncoder(os.Stdout)
+      encoder.SetIndent("", "  ")
+      _ = encoder.Encode(struct {
+        Valid bool `json:"valid"`
+        Events []event.ConformanceReport `json:"events"`
+      }{valid, reports})
+    } else {
+      failed := 0
+      for _, report := range reports {
+        status := "ok"
+        if !report.Valid {
+          status = "invalid"
+          failed++
+        }
+        fmt.Printf("%s[%d] %s (%s): %s\n", report.File, report.Index, report.ID, report.Input, status)
+        for _, issue := range report.Issues {
+          fmt.Printf("  %s\n", issue)
+        }
+      }
+      log.Printf("%d of %d event(s) invalid", failed, len(reports))
+    }
+
+    if !valid {
+      os.Exit(int(event.ErrInvalidFormat))
+    }
+  },
+}
+
+// validateFile checks every event in a file, adding schema violations for
+// events that conform to the spec.
+func validateFile(file string, schemas *event.SchemaValidator) ([]event.ConformanceReport, error) {
+  content, _, err := event.ReadPayloadFile(file)
+  if err != nil {
+    return nil, err
+  }
+
+  events, input, err := event.ReadRawEvents(content)
+  if err != nil {
+    return nil, event.Wrap(event.ErrInvalidFormat, err, fmt.Sprintf("%s: %s", file, event.Message(err)))
+  }
+
+  reports := make([]event.ConformanceReport, 0, len(events))
+  for index, raw := range events {
+    report := event.ConformanceReport{File: file, Index: index, Input: input, Issues: event.CheckConformance(raw)}
+    if id, ok := raw["id"]; ok {
+      _ = json.Unmarshal(id, &report.ID)
+    }
+
+    if schemas != nil && !hasErrors(report.Issues) {
+      report.Issues = append(report.Issues, checkSchema(raw, schemas)...)
+    }
+
+    report.Valid = !hasErrors(report.Issues) && !(strict && len(report.Issues) > 0)
+    reports = append(reports, report)
+  }
+  return reports, nil
+}
+
+// checkSchema reports the JSON Schema violations of the event data.
+func checkSchema(raw event.RawEvent, schemas *event.SchemaValidator) []event.Issue {
+  ce, err := raw.Event()
+  if err != nil {
+    return []event.Issue{{Severity: event.SeverityError, Rule: "format", Message: err.Error()}}
+  }
+
+  schema, violations, err := schemas.Validate(ce)
+  if err != nil {
+    return []event.Issue{{Severity: event.SeverityError, Attribute: "dataschema", Rule: "schema", Message: err.Error()}}
+  }
+
+  issues := make([]event.Issue, 0, len(violations))
+  for _, violation := range violations {
+    issues = append(issues, event.Issue{Severity: event.SeverityError, Attribute: "data", Rule: "schema", Message: fmt.Sprintf("%s (%s)", violation, schema)})
+  }
+  return issues
+}
+
+func hasErrors(issues []event.Issue) bool {
+  for _, issue := range issues {
+    if issue.Severity == event.SeverityError {
+      return true
+    }
+  }
+  return false
+}
+
+func init() {
+  ValidateEventCmd.Flags().StringVarP(&output, "output", "o", "text", "Output format (text, json)")
+  ValidateEventCmd.Flags().BoolVar(&strict, "strict", false, "Treat warnings as failures")
+}
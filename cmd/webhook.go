@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	event "github.com/anselmes/ce-go-template/event"
 	"github.com/spf13/cobra"
@@ -22,19 +23,41 @@ var EventWebhookCmd = &cobra.Command {
   Run: func(cmd *cobra.Command, args []string) {
     log.Println("Starting webhook server to handle CloudEvents...")
 
-    if err := initializeClient(); err != nil {
-      log.Fatalln(event.Error(event.ErrReceiveFailed, err.Error()))
+    if err := loadConfig(cmd); err != nil {
+      log.Fatalln(err)
+    }
+
+    if err := initializeManager(); err != nil {
+      log.Fatalln(event.Wrap(event.ErrReceiveFailed, err))
     }
 
     // Set up HTTP handler for CloudEvents
-    http.Handle("/", manager.Handler())
+    server := &http.Server{
+      Addr:    fmt.Sprintf("%s:%d", config.Address, config.Port),
+      Handler: manager.Handler(),
+    }
+
+    listener, err := config.Listener()
+    if err != nil {
+      log.Fatalln(err)
+    }
+
+    ctx, stop := signalContext(ctx)
+    defer stop()
 
     // Start HTTP server
     // log.Println("Listening on:", config.Url())
-    log.Printf("Listening on: http://%s:%d\n", config.Address, config.Port)
-    err := http.ListenAndServe(fmt.Sprintf("%s:%d", config.Address, config.Port), nil)
-    if err != nil {
-      log.Fatalln(event.Error(event.ErrUnknown, err.Error()))
+    if config.IsSocket() {
+      log.Printf("Listening on: %s\n", config.Endpoint())
+    } else {
+      log.Printf("Listening on: http://%s:%d\n", config.Address, config.Port)
+    }
+    if err := event.Serve(ctx, server, listener, config.ShutdownTimeout); err != nil {
+      log.Fatalln(event.Wrap(event.ErrUnknown, err))
     }
   },
 }
+
+func init() {
+  EventWebhookCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 10*time.Second, "Grace period to drain in-flight events on shutdown")
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/go-amqp"
+	ceamqp "github.com/cloudevents/sdk-go/protocol/amqp/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+)
+
+// AmqpConfig selects the server, node address and link settings used by the
+// AMQP 1.0 binding.
+type AmqpConfig struct {
+  Server string `envconfig:"CE_AMQP_SERVER"`
+  Address string `envconfig:"CE_AMQP_ADDRESS" default:"cloudevents"`
+  LinkName string `envconfig:"CE_AMQP_LINK_NAME"`
+  Credit int `envconfig:"CE_AMQP_CREDIT" default:"10"`
+}
+
+func (config CloudEventConfig) amqpClient(ctx context.Context) (cloudevents.Client, error) {
+  mode, err := ParseMode(config.Mode)
+  if err != nil {
+    return nil, err
+  }
+
+  options, err := config.amqpOptions(ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  link := []ceamqp.Option{}
+  if config.Amqp.LinkName != "" {
+    link = append(link, ceamqp.WithSenderLinkOption(amqp.LinkName(config.Amqp.LinkName)))
+  }
+
+  sender, err := ceamqp.NewSenderProtocol(config.amqpServer(), config.Amqp.Address, options, nil, link...)
+  if err != nil {
+    return nil, Error(ErrSendFailed, fmt.Sprintf("Failed to create AMQP sender: %v", err))
+  }
+  return newClient(sender, mode, cloudevents.WithTimeNow(), cloudevents.WithUUIDs())
+}
+
+func (config CloudEventConfig) amqpReceiver(ctx context.Context) (cloudevents.Client, error) {
+  options, err := config.amqpOptions(ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  // In the first settlement mode messages are accepted as they arrive, so
+  // they could no longer be released or rejected once the callback ran
+  link := []ceamqp.Option{ceamqp.WithReceiverLinkOption(amqp.LinkReceiverSettle(amqp.ModeSecond))}
+  if config.Amqp.Credit > 0 {
+    link = append(link, ceamqp.WithReceiverLinkOption(amqp.LinkCredit(uint32(config.Amqp.Credit))))
+  }
+  if config.Amqp.LinkName != "" {
+    link = append(link, ceamqp.WithReceiverLinkOption(amqp.LinkName(config.Amqp.LinkName)))
+  }
+
+  receiver, err := ceamqp.NewReceiverProtocol(config.amqpServer(), config.Amqp.Address, options, nil, link...)
+  if err != nil {
+    return nil, Error(ErrReceiveFailed, fmt.Sprintf("Failed to create AMQP receiver: %v", err))
+  }
+  return newClient(&amqpReceiver{receiver}, binding.EncodingUnknown)
+}
+
+func (config CloudEventConfig) amqpOptions(ctx context.Context) ([]amqp.ConnOption, error) {
+  options := []amqp.ConnOption{amqp.ConnSASLAnonymous()}
+  if !config.Insecure {
+    if err := config.loadTLS(ctx, urlHosts(config.amqpServer())...); err != nil {
+      return nil, err
+    }
+    options = append(options, amqp.ConnTLSConfig(config.Config))
+  }
+  return options, nil
+}
+
+// amqpServer falls back to the configured address and port when no server is set.
+func (config CloudEventConfig) amqpServer() string {
+  if config.Amqp.Server != "" {
+    return config.Amqp.Server
+  }
+
+  scheme := "amqps"
+  if config.Insecure {
+    scheme = "amqp"
+  }
+  return fmt.Sprintf("%s://%s:%d", scheme, config.Address, config.Port)
+}
+
+// MARK: - Settlement
+
+// amqpReceiver settles each message according to the callback result.
+type amqpReceiver struct {
+  *ceamqp.Protocol
+}
+
+func (receiver *amqpReceiver) Receive(ctx context.Context) (binding.Message, error) {
+  message, err := receiver.Protocol.Receive(ctx)
+  if err != nil {
+    return nil, err
+  }
+  if amqpMessage, ok := message.(*ceamqp.Message); ok {
+    return &settledMessage{amqpMessage}, nil
+  }
+  return message, nil
+}
+
+// settledMessage accepts messages the callback handled, releases them back to
+// the broker when the callback was cancelled, and rejects them otherwise.
+type settledMessage struct {
+  *ceamqp.Message
+}
+
+func (message *settledMessage) Finish(result error) error {
+  ctx := context.Background()
+
+  switch {
+  case protocol.IsACK(result):
+    return message.AMQPrcv.AcceptMessage(ctx, message.AMQP)
+  case errors.Is(result, context.Canceled), errors.Is(result, context.DeadlineExceeded):
+    return message.AMQPrcv.ReleaseMessage(ctx, message.AMQP)
+  default:
+    return message.AMQPrcv.RejectMessage(ctx, message.AMQP, &amqp.Error{
+      Condition:   amqp.ErrorCondition("cloudevents:callback-failed"),
+      Description: result.Error(),
+    })
+  }
+}
@@ -0,0 +1,394 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// AMQP 1.0 descriptor codes of the frames and sections the fake broker uses.
+const (
+  amqpOpen = 0x10
+  amqpBegin = 0x11
+  amqpAttach = 0x12
+  amqpFlow = 0x13
+  amqpTransfer = 0x14
+  amqpDisposition = 0x15
+  amqpDetach = 0x16
+  amqpEnd = 0x17
+  amqpClose = 0x18
+  amqpAccepted = 0x24
+  amqpRejected = 0x25
+  amqpReleased = 0x26
+  amqpSaslMechanisms = 0x40
+  amqpSaslOutcome = 0x44
+  amqpProperties = 0x73
+  amqpData = 0x75
+)
+
+// amqpBroker is just enough of an AMQP 1.0 peer to attach one receiver,
+// deliver queued messages to it and record how each one was settled.
+type amqpBroker struct {
+  listener net.Listener
+  messages [][]byte
+  mutex sync.Mutex
+  settled map[uint32]byte
+  conditions map[uint32]string
+  done chan struct{}
+}
+
+func startAmqpBroker(t *testing.T, messages ...[]byte) *amqpBroker {
+  t.Helper()
+
+  listener, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatal(err)
+  }
+  broker := &amqpBroker{
+    listener: listener,
+    messages: messages,
+    settled: map[uint32]byte{},
+    conditions: map[uint32]string{},
+    done: make(chan struct{}, len(messages)),
+  }
+  t.Cleanup(func() { _ = listener.Close() })
+
+  go func() {
+    conn, err := listener.Accept()
+    if err != nil {
+      return
+    }
+    defer func() { _ = conn.Close() }()
+    if err := broker.serve(conn); err != nil && !errors.Is(err, io.EOF) {
+      t.Errorf("fake AMQP broker: %v", err)
+    }
+  }()
+  return broker
+}
+
+// outcome returns the outcome the receiver settled delivery id with.
+func (broker *amqpBroker) outcome(id uint32) (byte, string) {
+  broker.mutex.Lock()
+  defer broker.mutex.Unlock()
+  return broker.settled[id], broker.conditions[id]
+}
+
+func (broker *amqpBroker) serve(conn net.Conn) error {
+  // SASL ANONYMOUS, then the AMQP protocol itself
+  if err := exchangeHeader(conn, "AMQP\x03\x01\x00\x00"); err != nil {
+    return err
+  }
+  if err := writeFrame(conn, 1, described(amqpSaslMechanisms, amqpList(amqpSymbols("ANONYMOUS")))); err != nil {
+    return err
+  }
+  if _, _, err := readFrame(conn); err != nil {
+    return err
+  }
+  if err := writeFrame(conn, 1, described(amqpSaslOutcome, amqpList([]byte{0x50, 0}))); err != nil {
+    return err
+  }
+  if err := exchangeHeader(conn, "AMQP\x00\x01\x00\x00"); err != nil {
+    return err
+  }
+
+  var handle []byte
+  delivered := false
+  for {
+    code, fields, err := readFrame(conn)
+    if err != nil {
+      return err
+    }
+
+    var reply []byte
+    switch code {
+    case amqpOpen:
+      reply = described(amqpOpen, amqpList(amqpString("broker")))
+    case amqpBegin:
+      reply = described(amqpBegin, amqpList([]byte{0x60, 0, 0}, amqpUint(0), amqpUint(1000), amqpUint(1000)))
+    case amqpAttach:
+      // Mirror the receiver, honouring the settlement mode it asked for
+      handle = field(fields, 1)
+      reply = described(amqpAttach, amqpList(
+        field(fields, 0), handle, []byte{0x42}, []byte{0x50, 0}, field(fields, 4),
+        field(fields, 5), field(fields, 6), []byte{0x40}, []byte{0x42}, amqpUint(0),
+      ))
+    case amqpFlow:
+      if delivered {
+        continue
+      }
+      delivered = true
+      for id, message := range broker.messages {
+        transfer := described(amqpTransfer, amqpList(
+          handle, amqpUint(uint32(id)), amqpBinary([]byte{byte(id)}), amqpUint(0), []byte{0x42},
+        ))
+        if err := writeFrame(conn, 0, append(transfer, message...)); err != nil {
+          return err
+        }
+      }
+    case amqpDisposition:
+      id := binary.BigEndian.Uint32(amqpUint32(field(fields, 1)))
+      state := field(fields, 4)
+      broker.mutex.Lock()
+      broker.settled[id] = state[2]
+      if state[2] == amqpRejected {
+        broker.conditions[id] = string(symbolIn(state))
+      }
+      broker.mutex.Unlock()
+      broker.done <- struct{}{}
+
+      // Settle the delivery on the broker side too, as the second mode expects
+      if !bytes.Equal(field(fields, 3), []byte{0x41}) {
+        reply = described(amqpDisposition, amqpList([]byte{0x42}, field(fields, 1), []byte{0x40}, []byte{0x41}, state))
+      }
+    case amqpDetach:
+      reply = described(amqpDetach, amqpList(field(fields, 0), []byte{0x41}))
+    case amqpEnd:
+      reply = described(amqpEnd, amqpList())
+    case amqpClose:
+      return writeFrame(conn, 0, described(amqpClose, amqpList()))
+    }
+    if reply != nil {
+      if err := writeFrame(conn, 0, reply); err != nil {
+        return err
+      }
+    }
+  }
+}
+
+// structuredMessage encodes an event as an AMQP message in structured mode.
+func structuredMessage(t *testing.T, id string) []byte {
+  t.Helper()
+
+  event := cloudevents.NewEvent()
+  event.SetID(id)
+  event.SetSource("/orders")
+  event.SetType("com.example.order")
+  payload, err := event.MarshalJSON()
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  // content-type is the seventh field of the properties section
+  properties := [][]byte{{0x40}, {0x40}, {0x40}, {0x40}, {0x40}, {0x40}, amqpSymbol("application/cloudevents+json")}
+  return append(described(amqpProperties, amqpList(properties...)), described(amqpData, amqpBinary(payload))...)
+}
+
+func TestAmqpSettlesOnTheCallbackResult(t *testing.T) {
+  broker := startAmqpBroker(t, structuredMessage(t, "handled"), structuredMessage(t, "failed"), structuredMessage(t, "cancelled"))
+  config := &CloudEventConfig{
+    Protocol: ProtocolAMQP,
+    Insecure: true,
+    Amqp: AmqpConfig{Server: "amqp://" + broker.listener.Addr().String(), Address: "orders", LinkName: "cecli-test", Credit: 10},
+  }
+
+  listen(t, config, func(ctx context.Context, event cloudevents.Event) error {
+    switch event.ID() {
+    case "failed":
+      return errors.New("callback failed")
+    case "cancelled":
+      return context.Canceled
+    }
+    return nil
+  })
+  for range 3 {
+    waitFor(t, "the settlements", func() bool {
+      select {
+      case <-broker.done:
+        return true
+      default:
+        return false
+      }
+    })
+  }
+
+  tests := []struct {
+    id uint32
+    outcome byte
+    name string
+  }{
+    {0, amqpAccepted, "accepted"},
+    {1, amqpRejected, "rejected"},
+    {2, amqpReleased, "released"},
+  }
+  for _, test := range tests {
+    outcome, condition := broker.outcome(test.id)
+    if outcome != test.outcome {
+      t.Errorf("delivery %d settled as %#x, want %s (%#x)", test.id, outcome, test.name, test.outcome)
+    }
+    if test.outcome == amqpRejected && condition != "cloudevents:callback-failed" {
+      t.Errorf("delivery %d rejected with %q, want cloudevents:callback-failed", test.id, condition)
+    }
+  }
+}
+
+// MARK: - Encoding
+
+func exchangeHeader(conn net.Conn, header string) error {
+  received := make([]byte, len(header))
+  if _, err := io.ReadFull(conn, received); err != nil {
+    return err
+  }
+  if string(received) != header {
+    return fmt.Errorf("protocol header %q, want %q", received, header)
+  }
+  _, err := conn.Write([]byte(header))
+  return err
+}
+
+// readFrame returns the descriptor code and raw fields of the next frame,
+// skipping empty keepalive frames.
+func readFrame(conn net.Conn) (byte, [][]byte, error) {
+  for {
+    header := make([]byte, 8)
+    if _, err := io.ReadFull(conn, header); err != nil {
+      return 0, nil, err
+    }
+    frame := make([]byte, binary.BigEndian.Uint32(header)-8)
+    if _, err := io.ReadFull(conn, frame); err != nil {
+      return 0, nil, err
+    }
+    body := frame[int(header[4])*4-8:]
+    if len(body) == 0 {
+      continue
+    }
+
+    // 0x00 0x53 code, then the list of fields
+    list := body[3:]
+    var fields [][]byte
+    switch list[0] {
+    case 0x45:
+    case 0xc0:
+      fields = splitValues(list[3 : 2+int(list[1])], int(list[2]))
+    case 0xd0:
+      size := binary.BigEndian.Uint32(list[1:])
+      fields = splitValues(list[9:5+size], int(binary.BigEndian.Uint32(list[5:])))
+    default:
+      return 0, nil, fmt.Errorf("unexpected performative encoding %#x", list[0])
+    }
+    return body[2], fields, nil
+  }
+}
+
+func writeFrame(conn net.Conn, kind byte, body []byte) error {
+  frame := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+  frame = append(frame, 2, kind, 0, 0)
+  _, err := conn.Write(append(frame, body...))
+  return err
+}
+
+// splitValues splits count encoded values into their raw bytes.
+func splitValues(data []byte, count int) [][]byte {
+  values := make([][]byte, 0, count)
+  for range count {
+    size := valueSize(data)
+    values = append(values, data[:size])
+    data = data[size:]
+  }
+  return values
+}
+
+// valueSize is the length of the encoded value at the start of data.
+func valueSize(data []byte) int {
+  code := data[0]
+  switch {
+  case code == 0x00:
+    descriptor := 1 + valueSize(data[1:])
+    return descriptor + valueSize(data[descriptor:])
+  case code >= 0x40 && code <= 0x45:
+    return 1
+  case code >= 0x50 && code <= 0x56:
+    return 2
+  case code >= 0x60 && code <= 0x6f:
+    return 3
+  case code >= 0x70 && code <= 0x7f:
+    return 5
+  case code >= 0x80 && code <= 0x8f:
+    return 9
+  case code == 0x98:
+    return 17
+  case code == 0xa0, code == 0xa1, code == 0xa3, code == 0xc0, code == 0xc1, code == 0xe0:
+    return 2 + int(data[1])
+  default:
+    return 5 + int(binary.BigEndian.Uint32(data[1:]))
+  }
+}
+
+// field returns a field, null when the list was cut short before it.
+func field(fields [][]byte, index int) []byte {
+  if index < len(fields) {
+    return fields[index]
+  }
+  return []byte{0x40}
+}
+
+// amqpUint32 widens the smallest encodings of a uint to four bytes.
+func amqpUint32(value []byte) []byte {
+  switch value[0] {
+  case 0x43:
+    return []byte{0, 0, 0, 0}
+  case 0x52:
+    return []byte{0, 0, 0, value[1]}
+  default:
+    return value[1:5]
+  }
+}
+
+// symbolIn returns the first symbol found in an encoded value.
+func symbolIn(value []byte) []byte {
+  for index := 0; index < len(value)-1; index++ {
+    if value[index] == 0xa3 && index+2+int(value[index+1]) <= len(value) {
+      return value[index+2 : index+2+int(value[index+1])]
+    }
+  }
+  return nil
+}
+
+func described(code byte, value []byte) []byte {
+  return append([]byte{0x00, 0x53, code}, value...)
+}
+
+func amqpList(values ...[]byte) []byte {
+  content := bytes.Join(values, nil)
+  list := []byte{0xd0}
+  list = binary.BigEndian.AppendUint32(list, uint32(4+len(content)))
+  list = binary.BigEndian.AppendUint32(list, uint32(len(values)))
+  return append(list, content...)
+}
+
+func amqpUint(value uint32) []byte {
+  return binary.BigEndian.AppendUint32([]byte{0x70}, value)
+}
+
+func amqpString(value string) []byte {
+  return append(binary.BigEndian.AppendUint32([]byte{0xb1}, uint32(len(value))), value...)
+}
+
+func amqpSymbol(value string) []byte {
+  return append(binary.BigEndian.AppendUint32([]byte{0xb3}, uint32(len(value))), value...)
+}
+
+func amqpBinary(value []byte) []byte {
+  return append(binary.BigEndian.AppendUint32([]byte{0xb0}, uint32(len(value))), value...)
+}
+
+func amqpSymbols(values ...string) []byte {
+  content := []byte{0xb3}
+  for _, value := range values {
+    content = append(binary.BigEndian.AppendUint32(content, uint32(len(value))), value...)
+  }
+  array := []byte{0xf0}
+  array = binary.BigEndian.AppendUint32(array, uint32(4+len(content)))
+  array = binary.BigEndian.AppendUint32(array, uint32(len(values)))
+  return append(array, content...)
+}
@@ -0,0 +1,201 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// BatchSender is a client able to deliver several events in one request.
+type BatchSender interface {
+  cloudevents.Client
+  SendBatch(ctx context.Context, events []cloudevents.Event) protocol.Result
+}
+
+// BatchClient is a cloudevents.Client that delivers events using the JSON
+// batch format (application/cloudevents-batch+json).
+type BatchClient struct {
+  target string
+  client *http.Client
+}
+
+func NewBatchClient(target string, client *http.Client) *BatchClient {
+  return &BatchClient{target: target, client: client}
+}
+
+func (batch *BatchClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+  return batch.SendBatch(ctx, []cloudevents.Event{event})
+}
+
+// SendBatch posts all events in a single request.
+func (batch *BatchClient) SendBatch(ctx context.Context, events []cloudevents.Event) protocol.Result {
+  req, err := cehttp.NewHTTPRequestFromEvents(ctx, batch.target, events)
+  if err != nil {
+    return Wrap(ErrInvalidFormat, err)
+  }
+
+  res, err := batch.client.Do(req)
+  if err != nil {
+    return protocol.NewReceipt(false, "%w", err)
+  }
+  defer func() { _ = res.Body.Close() }()
+
+  body, _ := io.ReadAll(res.Body)
+
+  // A multi-status reply means some events in the batch were rejected
+  if res.StatusCode == http.StatusMultiStatus {
+    return cehttp.NewResult(res.StatusCode, "%w: %s", protocol.ResultNACK, rejected(body))
+  }
+  if res.StatusCode/100 == 2 {
+    return cehttp.NewResult(res.StatusCode, "%w", protocol.ResultACK)
+  }
+  return cehttp.NewResult(res.StatusCode, "%w: %s", protocol.ResultNACK, body)
+}
+
+func (batch *BatchClient) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+  return nil, batch.Send(ctx, event)
+}
+
+func (batch *BatchClient) StartReceiver(ctx context.Context, fn interface{}) error {
+  return Error(ErrReceiveFailed, "batch mode does not support receiving through a client")
+}
+
+// MARK: - Sending
+
+// SendBatch delivers events in groups of size, retrying each group under the
+// manager's retry policy. A report is returned for every group attempted.
+func (manager *CloudEventManager) SendBatch(ctx context.Context, client BatchSender, events []cloudevents.Event, size int) ([]*DeliveryReport, error) {
+  if size < 1 {
+    size = len(events)
+  }
+
+  reports := []*DeliveryReport{}
+  failed := 0
+  var code CloudEventErrorCodes
+
+  for start := 0; start < len(events); start += size {
+    chunk := events[start:min(start+size, len(events))]
+    log.Printf("Sending batch of %d CloudEvent(s), %d/%d", len(chunk), start+len(chunk), len(events))
+
+    report, err := manager.deliver(ctx, func(ctx context.Context) protocol.Result {
+      return client.SendBatch(ctx, chunk)
+    })
+    reports = append(reports, report)
+
+    if err != nil {
+      log.Printf("Batch starting at event %d failed: %v", start+1, err)
+      failed++
+      if code == 0 {
+        code = CloudEventErrorCodes(report.ExitCode())
+      }
+    }
+    if ctx.Err() != nil {
+      break
+    }
+  }
+
+  if failed > 0 {
+    return reports, Error(code, fmt.Sprintf("%d of %d batch(es) failed", failed, len(reports)))
+  }
+  return reports, nil
+}
+
+// ReadEvents parses newline delimited structured CloudEvents, skipping blank lines.
+func ReadEvents(reader io.Reader) ([]cloudevents.Event, error) {
+  events := []cloudevents.Event{}
+  scanner := bufio.NewScanner(reader)
+  scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+  for line := 1; scanner.Scan(); line++ {
+    text := bytes.TrimSpace(scanner.Bytes())
+    if len(text) == 0 {
+      continue
+    }
+
+    event := cloudevents.NewEvent()
+    if err := json.Unmarshal(text, &event); err != nil {
+      return nil, Error(ErrInvalidFormat, fmt.Sprintf("line %d: %v", line, err))
+    }
+    if err := event.Validate(); err != nil {
+      return nil, Error(ErrInvalidFormat, fmt.Sprintf("line %d: %v", line, err))
+    }
+    events = append(events, event)
+  }
+
+  if err := scanner.Err(); err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+  return events, nil
+}
+
+// MARK: - Receiving
+
+// BatchResult is the outcome of a single event within a batch request.
+type BatchResult struct {
+  ID     string `json:"id"`
+  Status int    `json:"status"`
+  Error  string `json:"error,omitempty"`
+}
+
+// handleBatch invokes the callback once per event and replies with the
+// per-event results, using 207 Multi-Status when any of them failed.
+func (manager *CloudEventManager) handleBatch(w http.ResponseWriter, req *http.Request) {
+  events, err := cloudevents.NewEventsFromHTTPRequest(req)
+  if err != nil {
+    http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+    return
+  }
+
+  status := http.StatusOK
+  results := make([]BatchResult, 0, len(events))
+
+  for _, event := range events {
+    result := BatchResult{ID: event.ID(), Status: http.StatusOK}
+    if ctx, err := manager.accept(req.Context(), event); err != nil {
+      result.Status = http.StatusBadRequest
+      result.Error = err.Error()
+      status = http.StatusMultiStatus
+    } else if err := manager.handle(ctx, event); err != nil {
+      result.Status = http.StatusInternalServerError
+      result.Error = err.Error()
+      status = http.StatusMultiStatus
+    }
+    results = append(results, result)
+  }
+
+  w.Header().Set("Content-Type", cloudevents.ApplicationJSON)
+  w.WriteHeader(status)
+  _ = json.NewEncoder(w).Encode(results)
+}
+
+// rejected summarizes the failed entries of a multi-status batch reply.
+func rejected(body []byte) string {
+  var results []BatchResult
+  if err := json.Unmarshal(body, &results); err != nil {
+    return string(body)
+  }
+  return summarize(results)
+}
+
+func summarize(results []BatchResult) string {
+  failed := []string{}
+  for _, result := range results {
+    if result.Status/100 != 2 {
+      failed = append(failed, fmt.Sprintf("%s (%d: %s)", result.ID, result.Status, result.Error))
+    }
+  }
+  return fmt.Sprintf("%d of %d event(s) rejected: %s", len(failed), len(results), strings.Join(failed, ", "))
+}
@@ -4,87 +4,261 @@
 package cloudevent
 
 import (
+	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
-	"os"
+	"strings"
+	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/client"
+	"github.com/kelseyhightower/envconfig"
+)
+
+const (
+  ProtocolHTTP = "http"
+  ProtocolKafka = "kafka"
+  ProtocolNATS = "nats"
+  ProtocolJetStream = "jetstream"
+  ProtocolMQTT = "mqtt"
+  ProtocolAMQP = "amqp"
+  ProtocolGRPC = "grpc"
+  ProtocolWebSocket = "websocket"
 )
 
 type CloudEventConfig struct {
   Address string `envconfig:"CE_ADDRESS" default:"localhost"`
   Certificate string `envconfig:"CE_CERT" default:"tls-bundle.pem"`
   CertificateKey string `envconfig:"CE_KEY" default:"tls-key.pem"`
+  CA string `envconfig:"CE_CA"`
+  ClientCertificate string `envconfig:"CE_CLIENT_CERT"`
+  ClientKey string `envconfig:"CE_CLIENT_KEY"`
+  ServerCertificate string `envconfig:"CE_SERVER_CERT"`
+  ServerKey string `envconfig:"CE_SERVER_KEY"`
+  ClientAuth string `envconfig:"CE_CLIENT_AUTH" default:"none"`
   Insecure bool `envconfig:"CE_INSECURE" default:"false"`
   Port    int `envconfig:"CE_PORT" default:"8080"`
+  Socket string `envconfig:"CE_SOCKET"`
   SkipVerify bool `envconfig:"CE_SKIP_VERIFY" default:"false"`
-  Config *tls.Config
+  Protocol string `envconfig:"CE_TRANSPORT" default:"http"`
+  Mode string `envconfig:"CE_MODE" default:"binary"`
+  Format string `envconfig:"CE_FORMAT" default:"json"`
+  ShutdownTimeout time.Duration `envconfig:"CE_SHUTDOWN_TIMEOUT" default:"10s"`
+  Source string `envconfig:"CE_SOURCE"`
+  Type string `envconfig:"CE_TYPE"`
+  UnknownType string `envconfig:"CE_UNKNOWN_TYPE" default:"pass-through"`
+  SchemaDir string `envconfig:"CE_SCHEMA_DIR"`
+  FetchSchemas bool `envconfig:"CE_FETCH_SCHEMAS" default:"false"`
+  Retry RetryConfig `ignored:"true"`
+  Kafka KafkaConfig `ignored:"true"`
+  Nats NatsConfig `ignored:"true"`
+  Mqtt MqttConfig `ignored:"true"`
+  Amqp AmqpConfig `ignored:"true"`
+  WebSocket WebSocketConfig `ignored:"true"`
+  Config *tls.Config `ignored:"true"`
 }
 
-func (config CloudEventConfig) Client() (cloudevents.Client, error) {
-  var client cloudevents.Client
-
-  if config.Insecure {
-    log.Printf("Insecure mode enabled, skipping TLS verification")
+// LoadConfig resolves the configuration from CE_* environment variables, then
+// the given context for anything the environment leaves unset, then defaults.
+func LoadConfig(selected *Context) (*CloudEventConfig, error) {
+  config := &CloudEventConfig{}
 
-    // Create protocol and client for insecure mode
-    protocol, err := cloudevents.NewHTTP(cloudevents.WithTarget(config.Url().String()))
-    if err != nil {
-      return nil, Error(ErrUnknown, err.Error())
-    }
-    client, err = cloudevents.NewClient(protocol, cloudevents.WithTimeNow(), cloudevents.WithUUIDs())
-    if err != nil {
-      return nil, Error(ErrUnknown, err.Error())
+  // Sections are processed on their own so their variables keep the CE_ prefix
+  sections := []interface{}{config, &config.Retry, &config.Kafka, &config.Nats, &config.Mqtt, &config.Amqp, &config.WebSocket}
+  for _, section := range sections {
+    if err := envconfig.Process("", section); err != nil {
+      return nil, Wrap(ErrInvalidFormat, err)
     }
-  } else {
-    pool := x509.NewCertPool()
+  }
+
+  if selected != nil {
+    selected.apply(config)
+  }
+  return config, nil
+}
+
+// Client creates a client for sending over the configured protocol. TLS
+// material is reloaded until ctx is done.
+func (config CloudEventConfig) Client(ctx context.Context) (cloudevents.Client, error) {
+  switch config.protocol() {
+  case ProtocolHTTP:
+    return config.httpClient(ctx)
+  case ProtocolKafka:
+    return config.kafkaClient(ctx)
+  case ProtocolNATS, ProtocolJetStream:
+    return config.natsClient(ctx)
+  case ProtocolMQTT:
+    return config.mqttClient(ctx)
+  case ProtocolAMQP:
+    return config.amqpClient(ctx)
+  case ProtocolGRPC:
+    return config.grpcClient(ctx)
+  case ProtocolWebSocket:
+    return config.websocketClient(ctx)
+  default:
+    return nil, Error(ErrInvalidURL, fmt.Sprintf("unsupported transport %q", config.Protocol))
+  }
+}
+
+// Receiver creates a client for receiving over protocols that are not served
+// by the HTTP Handler. TLS material is reloaded until ctx is done.
+func (config CloudEventConfig) Receiver(ctx context.Context) (cloudevents.Client, error) {
+  switch config.protocol() {
+  case ProtocolKafka:
+    return config.kafkaReceiver(ctx)
+  case ProtocolNATS, ProtocolJetStream:
+    return config.natsReceiver(ctx)
+  case ProtocolMQTT:
+    return config.mqttReceiver(ctx)
+  case ProtocolAMQP:
+    return config.amqpReceiver(ctx)
+  case ProtocolGRPC:
+    return config.grpcClient(ctx)
+  default:
+    return nil, Error(ErrInvalidURL, fmt.Sprintf("transport %q does not receive through a client", config.Protocol))
+  }
+}
 
+// IsHTTP reports whether events are received by an HTTP server, which also
+// accepts WebSocket upgrades.
+func (config CloudEventConfig) IsHTTP() bool {
+  return config.protocol() == ProtocolHTTP || config.protocol() == ProtocolWebSocket
+}
+
+// protocol normalizes the configured protocol name, defaulting to HTTP.
+func (config CloudEventConfig) protocol() string {
+  if config.Protocol == "" {
+    return ProtocolHTTP
+  }
+  return strings.ToLower(config.Protocol)
+}
+
+func (config CloudEventConfig) httpClient(ctx context.Context) (cloudevents.Client, error) {
+  mode, err := ParseMode(config.Mode)
+  if err != nil {
+    return nil, err
+  }
+
+  var transport http.RoundTripper = http.DefaultTransport
+  options := []client.Option{cloudevents.WithTimeNow()}
+
+  switch {
+  case config.IsSocket():
+    // File permissions on the socket take the place of TLS
+    transport = &http.Transport{DialContext: config.dialSocket}
+  case config.Insecure:
+    log.Printf("Insecure mode enabled, skipping TLS verification")
+    options = append(options, cloudevents.WithUUIDs())
+  default:
     // Configure a new http.Transport with TLS
-    cert, err := tls.LoadX509KeyPair(config.Certificate, config.CertificateKey)
-    if err != nil {
-      return nil, Error(ErrTlsConfig, err.Error())
+    if err := config.loadTLS(ctx, config.Url().Hostname()); err != nil {
+      return nil, err
     }
+    transport = config.Transport()
+  }
+  transport = retryAfterTransport{transport}
 
-    ca, err := os.ReadFile(config.Certificate)
-    if err != nil {
-      return nil, Error(ErrTlsConfig, err.Error())
+  if mode == binding.EncodingBatch {
+    if strings.EqualFold(config.Format, FormatProtobuf) {
+      return nil, Error(ErrInvalidFormat, "batch mode only supports the JSON format")
     }
+    return NewBatchClient(config.Url().String(), &http.Client{Transport: transport}), nil
+  }
 
-    pool.AppendCertsFromPEM(ca)
+  // Create protocol and client
+  protocol, err := cloudevents.NewHTTP(cloudevents.WithTarget(config.Url().String()), cloudevents.WithRoundTripper(transport))
+  if err != nil {
+    return nil, Wrap(ErrUnknown, err)
+  }
+  return newClient(protocol, mode, options...)
+}
 
-    config.Config = &tls.Config{
-      Certificates:       []tls.Certificate{cert},
-      RootCAs:            pool,
-      InsecureSkipVerify: config.SkipVerify,
-    }
+// newClient wraps a protocol in a client forcing the requested content mode.
+func newClient(protocol interface{}, mode binding.Encoding, options ...client.Option) (cloudevents.Client, error) {
+  switch mode {
+  case binding.EncodingBinary:
+    options = append(options, client.WithForceBinary())
+  case binding.EncodingStructured:
+    options = append(options, client.WithForceStructured())
+  case binding.EncodingBatch:
+    return nil, Error(ErrInvalidFormat, "batch mode is only supported over HTTP")
+  }
+
+  result, err := cloudevents.NewClient(protocol, options...)
+  if err != nil {
+    return nil, Wrap(ErrUnknown, err)
+  }
+  if closer, ok := protocol.(Closer); ok {
+    return closingClient{result, closer}, nil
+  }
+  return result, nil
+}
 
-    // Create protocol and client
-    protocol, err := cloudevents.NewHTTP(cloudevents.WithTarget(config.Url().String()), cloudevents.WithRoundTripper(config.Transport()))
+// Closer is implemented by clients that hold a connection until closed.
+type Closer interface {
+  Close(ctx context.Context) error
+}
+
+// closingClient closes the protocol of an SDK client, which the client
+// itself never does, so pending messages are flushed before exiting.
+type closingClient struct {
+  cloudevents.Client
+  Closer
+}
+
+// Close releases the connection of a client from Client or Receiver, if it
+// holds one.
+func Close(ctx context.Context, client cloudevents.Client) error {
+  if closer, ok := client.(Closer); ok {
+    return closer.Close(ctx)
+  }
+  return nil
+}
+
+func (config CloudEventConfig) Url() *url.URL {
+  switch config.protocol() {
+  case ProtocolKafka:
+    return &url.URL{Scheme: ProtocolKafka, Host: config.kafkaBrokers()[0], Path: "/" + config.Kafka.Topic}
+  case ProtocolNATS, ProtocolJetStream:
+    target, err := url.Parse(config.natsUrl())
     if err != nil {
-      return nil, Error(ErrUnknown, err.Error())
+      target = &url.URL{Scheme: ProtocolNATS}
     }
-    client, err = cloudevents.NewClient(protocol, cloudevents.WithTimeNow())
+    target.Path = "/" + config.Nats.Subject
+    return target
+  case ProtocolMQTT:
+    return &url.URL{Scheme: ProtocolMQTT, Host: config.mqttBroker(), Path: "/" + config.Mqtt.Topic}
+  case ProtocolAMQP:
+    target, err := url.Parse(config.amqpServer())
     if err != nil {
-      return nil, Error(ErrUnknown, err.Error())
+      target = &url.URL{Scheme: ProtocolAMQP}
     }
+    target.Path = "/" + config.Amqp.Address
+    return target
+  case ProtocolGRPC:
+    return &url.URL{Scheme: ProtocolGRPC, Host: fmt.Sprintf("%s:%d", config.Address, config.Port)}
   }
 
-  return client, nil
-}
-
-func (config CloudEventConfig) Url() *url.URL {
   scheme := "https"
-  if config.Insecure {
+  if config.Insecure || config.IsSocket() {
     scheme = "http"
   }
-  return &url.URL{
+  target := &url.URL{
     Scheme: scheme,
     Host:   fmt.Sprintf("%s:%d", config.Address, config.Port),
   }
+  if config.IsSocket() {
+    // The host is only used for the Host header, the socket is always dialed
+    target.Host = "localhost"
+  }
+  if config.protocol() == ProtocolWebSocket {
+    return websocketUrl(target)
+  }
+  return target
 }
 
 func (config CloudEventConfig) Transport() *http.Transport {
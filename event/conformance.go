@@ -0,0 +1,391 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+const (
+  InputStructured = "structured"
+  InputBatch = "batch"
+  InputJSONL = "jsonl"
+  InputBinaryHTTP = "binary-http"
+  InputStructuredHTTP = "structured-http"
+  InputBatchHTTP = "batch-http"
+
+  SeverityError = "error"
+  SeverityWarning = "warning"
+)
+
+// maxAttributeName is the length attribute names SHOULD NOT exceed.
+const maxAttributeName = 20
+
+var (
+  attributeName = regexp.MustCompile(`^[a-z0-9]+$`)
+  requestLine = regexp.MustCompile(`^[A-Z]+ \S+ HTTP/\d(\.\d)?\r?$`)
+)
+
+// contextAttributes are the attributes defined by CloudEvents 1.0, all of
+// which are strings in the JSON format.
+var contextAttributes = map[string]bool{
+  "id": true, "source": true, "specversion": true, "type": true,
+  "datacontenttype": true, "dataschema": true, "subject": true, "time": true,
+}
+
+// RawEvent is an event in the JSON format before the SDK gets to reject it,
+// so every problem can be reported.
+type RawEvent map[string]json.RawMessage
+
+// Event converts the raw event into an SDK event.
+func (raw RawEvent) Event() (cloudevents.Event, error) {
+  event := cloudevents.NewEvent()
+  content, err := json.Marshal(raw)
+  if err != nil {
+    return event, Wrap(ErrInvalidFormat, err)
+  }
+  if err := json.Unmarshal(content, &event); err != nil {
+    return event, Wrap(ErrInvalidFormat, err)
+  }
+  return event, nil
+}
+
+// string returns a string attribute, empty when absent or of another type.
+func (raw RawEvent) string(name string) string {
+  var value string
+  _ = json.Unmarshal(raw[name], &value)
+  return value
+}
+
+// Issue is one way an event breaks the CloudEvents 1.0 spec.
+type Issue struct {
+  Severity string `json:"severity"`
+  Attribute string `json:"attribute,omitempty"`
+  Rule string `json:"rule"`
+  Message string `json:"message"`
+}
+
+// ConformanceReport lists the issues of one event read from a file.
+type ConformanceReport struct {
+  File string `json:"file"`
+  Index int `json:"index"`
+  Input string `json:"input"`
+  ID string `json:"id,omitempty"`
+  Valid bool `json:"valid"`
+  Issues []Issue `json:"issues"`
+}
+
+func (issue Issue) String() string {
+  if issue.Attribute == "" {
+    return fmt.Sprintf("%s [%s] %s", issue.Severity, issue.Rule, issue.Message)
+  }
+  return fmt.Sprintf("%s [%s] %s: %s", issue.Severity, issue.Rule, issue.Attribute, issue.Message)
+}
+
+// MARK: - Reading
+
+// ReadRawEvents parses events from a structured JSON event, a JSON batch,
+// newline delimited JSON or HTTP request dumps in any content mode, and
+// reports which input it found.
+func ReadRawEvents(content []byte) ([]RawEvent, string, error) {
+  // Only leading space is dropped, a request without body ends in a blank line
+  content = bytes.TrimLeft(content, " \t\r\n")
+  if len(content) == 0 {
+    return nil, "", Error(ErrInvalidFormat, "no events found")
+  }
+
+  line, _, _ := bytes.Cut(content, []byte("\n"))
+  if requestLine.Match(line) {
+    return readRequests(content)
+  }
+  return readJSON(bytes.TrimSpace(content))
+}
+
+// readJSON decodes a batch array or a stream of event objects.
+func readJSON(content []byte) ([]RawEvent, string, error) {
+  if len(content) == 0 {
+    return nil, "", Error(ErrInvalidFormat, "no events found")
+  }
+  if content[0] == '[' {
+    var events []RawEvent
+    if err := json.Unmarshal(content, &events); err != nil {
+      return nil, "", Wrap(ErrInvalidFormat, err)
+    }
+    return events, InputBatch, nil
+  }
+
+  var events []RawEvent
+  decoder := json.NewDecoder(bytes.NewReader(content))
+  for decoder.More() {
+    var event RawEvent
+    if err := decoder.Decode(&event); err != nil {
+      return nil, "", Wrap(ErrInvalidFormat, err, fmt.Sprintf("event %d: %v", len(events), err))
+    }
+    events = append(events, event)
+  }
+
+  if len(events) == 1 {
+    return events, InputStructured, nil
+  }
+  return events, InputJSONL, nil
+}
+
+// readRequests parses one or more HTTP request dumps, separated by blank
+// lines. As on the wire, a request without Content-Length has no body.
+func readRequests(content []byte) ([]RawEvent, string, error) {
+  reader := bufio.NewReader(bytes.NewReader(content))
+
+  var events []RawEvent
+  input := ""
+  for index := 0; ; index++ {
+    if !skipSpace(reader) {
+      break
+    }
+    req, err := http.ReadRequest(reader)
+    if err != nil {
+      return nil, "", Wrap(ErrInvalidFormat, err, fmt.Sprintf("request %d: %v", index, err))
+    }
+    found, mode, err := readRequest(req)
+    if err != nil {
+      return nil, "", err
+    }
+    if input != "" && mode != input {
+      return nil, "", Error(ErrInvalidFormat, fmt.Sprintf("request %d is %s, not %s", index, mode, input))
+    }
+    input = mode
+    events = append(events, found...)
+  }
+  return events, input, nil
+}
+
+// skipSpace discards the blank lines between requests, reporting whether
+// anything follows them.
+func skipSpace(reader *bufio.Reader) bool {
+  for {
+    next, err := reader.Peek(1)
+    if err != nil {
+      return false
+    }
+    if !bytes.ContainsAny(next, " \t\r\n") {
+      return true
+    }
+    _, _ = reader.Discard(1)
+  }
+}
+
+// readRequest reads the events of one request in any content mode.
+func readRequest(req *http.Request) ([]RawEvent, string, error) {
+  body, err := io.ReadAll(req.Body)
+  if err != nil {
+    return nil, "", Wrap(ErrInvalidFormat, err)
+  }
+
+  contentType := req.Header.Get("Content-Type")
+  mediaType, _, _ := mime.ParseMediaType(contentType)
+  switch mediaType {
+  case cloudevents.ApplicationCloudEventsJSON:
+    events, _, err := readJSON(bytes.TrimSpace(body))
+    return events, InputStructuredHTTP, err
+  case cloudevents.ApplicationCloudEventsBatchJSON:
+    events, _, err := readJSON(bytes.TrimSpace(body))
+    return events, InputBatchHTTP, err
+  }
+
+  event := RawEvent{}
+  for name, values := range req.Header {
+    lower := strings.ToLower(name)
+    if !strings.HasPrefix(lower, "ce-") {
+      continue
+    }
+    value, err := url.PathUnescape(values[0])
+    if err != nil {
+      value = values[0]
+    }
+    event[strings.TrimPrefix(lower, "ce-")], _ = json.Marshal(value)
+  }
+
+  if contentType != "" {
+    event["datacontenttype"], _ = json.Marshal(contentType)
+  }
+  if len(body) > 0 {
+    switch {
+    case (contentType == "" || isJSON(contentType)) && json.Valid(body):
+      event["data"] = json.RawMessage(bytes.TrimSpace(body))
+    case contentType != "" && isText(contentType):
+      event["data"], _ = json.Marshal(string(body))
+    default:
+      event["data_base64"], _ = json.Marshal(base64.StdEncoding.EncodeToString(body))
+    }
+  }
+  return []RawEvent{event}, InputBinaryHTTP, nil
+}
+
+// MARK: - Checks
+
+// CheckConformance checks an event against the CloudEvents 1.0 spec and its
+// JSON format, returning every issue found.
+func CheckConformance(event RawEvent) []Issue {
+  issues := []Issue{}
+  report := func(severity, attribute, rule, format string, args ...any) {
+    issues = append(issues, Issue{Severity: severity, Attribute: attribute, Rule: rule, Message: fmt.Sprintf(format, args...)})
+  }
+
+  for _, name := range []string{"id", "source", "specversion", "type"} {
+    if value, ok := event[name]; !ok || bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+      report(SeverityError, name, "required", "required attribute is missing")
+    }
+  }
+
+  names := make([]string, 0, len(event))
+  for name := range event {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+
+  for _, name := range names {
+    value := event[name]
+    if name == "data" || name == "data_base64" {
+      continue
+    }
+
+    if !attributeName.MatchString(name) {
+      report(SeverityError, name, "naming", "attribute names must only contain lower-case letters and digits")
+    } else if len(name) > maxAttributeName {
+      report(SeverityWarning, name, "naming", "attribute names should not exceed %d characters", maxAttributeName)
+    }
+
+    if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+      continue
+    }
+
+    if !contextAttributes[name] {
+      if err := extensionValue(value); err != nil {
+        report(SeverityError, name, "extension-type", "%v", err)
+      }
+      continue
+    }
+
+    var text string
+    if err := json.Unmarshal(value, &text); err != nil {
+      report(SeverityError, name, "attribute-type", "attribute must be a string")
+      continue
+    }
+    if err := contextValue(name, text); err != nil {
+      report(SeverityError, name, name, "%v", err)
+    }
+  }
+
+  data, hasData := event["data"]
+  encoded, hasBase64 := event["data_base64"]
+  if hasData && hasBase64 {
+    report(SeverityError, "data", "data", "data and data_base64 are mutually exclusive")
+  }
+
+  contentType := event.string("datacontenttype")
+  if _, _, err := mime.ParseMediaType(contentType); contentType != "" && err != nil {
+    // Already reported against datacontenttype
+    return issues
+  }
+  described := contentType
+  if described == "" {
+    described = "application/json (implied)"
+  }
+
+  if hasBase64 {
+    var value string
+    if err := json.Unmarshal(encoded, &value); err != nil {
+      report(SeverityError, "data_base64", "datacontenttype", "data_base64 must be a string")
+      return issues
+    }
+    decoded, err := base64.StdEncoding.DecodeString(value)
+    switch {
+    case err != nil:
+      report(SeverityError, "data_base64", "datacontenttype", "data_base64 is not valid base64: %v", err)
+    case (contentType == "" || isJSON(contentType)) && !json.Valid(decoded):
+      report(SeverityError, "data_base64", "datacontenttype", "data is not valid JSON but datacontenttype is %s", described)
+    }
+  }
+
+  if hasData && contentType != "" && !isJSON(contentType) {
+    trimmed := bytes.TrimSpace(data)
+    switch {
+    case len(trimmed) > 0 && trimmed[0] != '"' && string(trimmed) != "null":
+      report(SeverityError, "data", "datacontenttype", "data is a JSON value but datacontenttype is %s", contentType)
+    case !isText(contentType):
+      report(SeverityWarning, "data", "datacontenttype", "binary data for %s should be carried in data_base64", contentType)
+    }
+  }
+  return issues
+}
+
+// contextValue checks the value of a context attribute.
+func contextValue(name string, value string) error {
+  switch name {
+  case "id", "type", "subject":
+    if value == "" {
+      return fmt.Errorf("must be a non-empty string")
+    }
+  case "specversion":
+    if value != "1.0" {
+      return fmt.Errorf("%q is not 1.0", value)
+    }
+  case "source":
+    if value == "" {
+      return fmt.Errorf("must be a non-empty URI-reference")
+    }
+    if _, err := url.Parse(value); err != nil {
+      return fmt.Errorf("not a URI-reference: %v", err)
+    }
+  case "dataschema":
+    target, err := url.Parse(value)
+    if err != nil || !target.IsAbs() {
+      return fmt.Errorf("%q is not an absolute URI", value)
+    }
+  case "time":
+    if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+      return fmt.Errorf("%q is not an RFC 3339 timestamp", value)
+    }
+  case "datacontenttype":
+    if _, _, err := mime.ParseMediaType(value); err != nil {
+      return fmt.Errorf("%q is not a media type: %v", value, err)
+    }
+  }
+  return nil
+}
+
+// extensionValue checks that an extension holds one of the types the JSON
+// format allows for it: string, boolean or a 32-bit integer.
+func extensionValue(value json.RawMessage) error {
+  var decoded any
+  if err := json.Unmarshal(value, &decoded); err != nil {
+    return err
+  }
+
+  switch typed := decoded.(type) {
+  case string, bool:
+    return nil
+  case float64:
+    if typed != math.Trunc(typed) || typed < math.MinInt32 || typed > math.MaxInt32 {
+      return fmt.Errorf("number %s is not a 32-bit integer", value)
+    }
+    return nil
+  default:
+    return fmt.Errorf("extension values must be strings, booleans or integers, not %s", value)
+  }
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const validEvent = `"specversion":"1.0","id":"order-42","source":"/orders","type":"com.example.order"`
+
+func TestCheckConformance(t *testing.T) {
+  tests := []struct {
+    name   string
+    event  string
+    issues []string
+  }{
+    {"valid", `{` + validEvent + `}`, nil},
+    {"missing required", `{"specversion":"1.0","id":null}`, []string{"error/required/id", "error/required/source", "error/required/type"}},
+    {"upper-case name", `{` + validEvent + `,"Tenant":"acme"}`, []string{"error/naming/Tenant"}},
+    {"long name", `{` + validEvent + `,"averyveryverylongextension":"x"}`, []string{"warning/naming/averyveryverylongextension"}},
+    {"extension integer", `{` + validEvent + `,"priority":7,"sampled":true}`, nil},
+    {"extension fraction", `{` + validEvent + `,"priority":1.5}`, []string{"error/extension-type/priority"}},
+    {"extension overflow", `{` + validEvent + `,"priority":4294967296}`, []string{"error/extension-type/priority"}},
+    {"extension object", `{` + validEvent + `,"tenant":{"id":1}}`, []string{"error/extension-type/tenant"}},
+    {"attribute type", `{"specversion":"1.0","id":42,"source":"/orders","type":"com.example.order"}`, []string{"error/attribute-type/id"}},
+    {"specversion", `{"specversion":"0.3","id":"order-42","source":"/orders","type":"com.example.order"}`, []string{"error/specversion/specversion"}},
+    {"empty source", `{"specversion":"1.0","id":"order-42","source":"","type":"com.example.order"}`, []string{"error/source/source"}},
+    {"time", `{` + validEvent + `,"time":"2025-01-02 03:04:05"}`, []string{"error/time/time"}},
+    {"rfc 3339 time", `{` + validEvent + `,"time":"2025-01-02T03:04:05.123Z"}`, nil},
+    {"relative dataschema", `{` + validEvent + `,"dataschema":"order.json"}`, []string{"error/dataschema/dataschema"}},
+    {"media type", `{` + validEvent + `,"datacontenttype":"not a type"}`, []string{"error/datacontenttype/datacontenttype"}},
+    {"data and data_base64", `{` + validEvent + `,"data":{},"data_base64":"e30="}`, []string{"error/data/data"}},
+    {"base64 not json", `{` + validEvent + `,"data_base64":"aGVsbG8="}`, []string{"error/datacontenttype/data_base64"}},
+    {"invalid base64", `{` + validEvent + `,"datacontenttype":"image/png","data_base64":"%%%"}`, []string{"error/datacontenttype/data_base64"}},
+    {"json value for text", `{` + validEvent + `,"datacontenttype":"text/plain","data":{"id":1}}`, []string{"error/datacontenttype/data"}},
+    {"string for binary", `{` + validEvent + `,"datacontenttype":"image/png","data":"png"}`, []string{"warning/datacontenttype/data"}},
+    {"text", `{` + validEvent + `,"datacontenttype":"text/plain","data":"hello"}`, nil},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      var event RawEvent
+      if err := json.Unmarshal([]byte(test.event), &event); err != nil {
+        t.Fatal(err)
+      }
+
+      var found []string
+      for _, issue := range CheckConformance(event) {
+        found = append(found, issue.Severity+"/"+issue.Rule+"/"+issue.Attribute)
+      }
+      if !reflect.DeepEqual(found, test.issues) {
+        t.Errorf("issues = %v, want %v", found, test.issues)
+      }
+    })
+  }
+}
+
+func TestReadRawEvents(t *testing.T) {
+  structured := `{` + validEvent + `,"data":{"id":"42"}}`
+  binary := "POST / HTTP/1.1\r\nHost: localhost\r\nCe-Specversion: 1.0\r\nCe-Id: order-42\r\nCe-Source: %2Forders\r\nCe-Type: com.example.order\r\n"
+
+  tests := []struct {
+    name    string
+    content string
+    input   string
+    ids     []string
+    err     string
+  }{
+    {"structured", structured, InputStructured, []string{"order-42"}, ""},
+    {"batch", `[` + structured + `,` + structured + `]`, InputBatch, []string{"order-42", "order-42"}, ""},
+    {"jsonl", structured + "\n" + structured + "\n", InputJSONL, []string{"order-42", "order-42"}, ""},
+    {"binary without data", binary + "\r\n", InputBinaryHTTP, []string{"order-42"}, ""},
+    {
+      "binary with data",
+      binary + "Content-Type: application/json\r\nContent-Length: 11\r\n\r\n{\"id\":\"42\"}",
+      InputBinaryHTTP, []string{"order-42"}, "",
+    },
+    {
+      "two binary requests",
+      binary + "Content-Length: 2\r\n\r\n{}\r\n\r\n" + strings.Replace(binary, "order-42", "order-43", 1) + "\r\n",
+      InputBinaryHTTP, []string{"order-42", "order-43"}, "",
+    },
+    {
+      "structured request",
+      "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/cloudevents+json\r\nContent-Length: " + strconv.Itoa(len(structured)) + "\r\n\r\n" + structured,
+      InputStructuredHTTP, []string{"order-42"}, "",
+    },
+    {
+      "batch request",
+      "POST / HTTP/1.1\r\nHost: localhost\r\nContent-Type: application/cloudevents-batch+json\r\nContent-Length: " + strconv.Itoa(len(structured)+2) + "\r\n\r\n[" + structured + "]",
+      InputBatchHTTP, []string{"order-42"}, "",
+    },
+    {"body without content-length", binary + "\r\n{\"id\":\"42\"}", "", nil, "malformed HTTP request"},
+    {"truncated headers", binary, "", nil, "unexpected EOF"},
+    {"empty", " \n", "", nil, "no events found"},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      events, input, err := ReadRawEvents([]byte(test.content))
+      if test.err != "" {
+        if err == nil || !strings.Contains(err.Error(), test.err) {
+          t.Fatalf("err = %v, want %q", err, test.err)
+        }
+        return
+      }
+      if err != nil {
+        t.Fatal(err)
+      }
+      if input != test.input {
+        t.Errorf("input = %q, want %q", input, test.input)
+      }
+
+      var ids []string
+      for _, event := range events {
+        ids = append(ids, event.string("id"))
+        if issues := CheckConformance(event); len(issues) > 0 {
+          t.Errorf("event %s is not conformant: %v", event.string("id"), issues)
+        }
+      }
+      if !reflect.DeepEqual(ids, test.ids) {
+        t.Errorf("ids = %v, want %v", ids, test.ids)
+      }
+    })
+  }
+}
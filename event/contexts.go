@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile holds named contexts, in the spirit of a kubeconfig.
+type ConfigFile struct {
+  CurrentContext string `yaml:"current-context,omitempty"`
+  Contexts []NamedContext `yaml:"contexts,omitempty"`
+}
+
+type NamedContext struct {
+  Name string `yaml:"name"`
+  Context Context `yaml:"context"`
+}
+
+// Context is a reusable target: where events go, over which transport, with
+// which TLS material, the default source and type, and the retry policy.
+// Fields are pointers so a context can also set false, 0 or "" explicitly.
+type Context struct {
+  Address *string `yaml:"address,omitempty"`
+  Port *int `yaml:"port,omitempty"`
+  Socket *string `yaml:"socket,omitempty"`
+  Transport *string `yaml:"transport,omitempty"`
+  Certificate *string `yaml:"cert,omitempty"`
+  CertificateKey *string `yaml:"key,omitempty"`
+  CA *string `yaml:"ca,omitempty"`
+  ClientCertificate *string `yaml:"client-cert,omitempty"`
+  ClientKey *string `yaml:"client-key,omitempty"`
+  ServerCertificate *string `yaml:"server-cert,omitempty"`
+  ServerKey *string `yaml:"server-key,omitempty"`
+  ClientAuth *string `yaml:"client-auth,omitempty"`
+  Insecure *bool `yaml:"insecure,omitempty"`
+  SkipVerify *bool `yaml:"skip-verify,omitempty"`
+  Source *string `yaml:"source,omitempty"`
+  Type *string `yaml:"type,omitempty"`
+  Retry ContextRetry `yaml:"retry,omitempty"`
+}
+
+// ContextRetry is the retry policy of a context, each setting optional.
+type ContextRetry struct {
+  Enable *bool `yaml:"enable,omitempty"`
+  Attempts *int `yaml:"attempts,omitempty"`
+  Backoff *string `yaml:"backoff,omitempty"`
+  Delay *time.Duration `yaml:"delay,omitempty"`
+  MaxDelay *time.Duration `yaml:"max-delay,omitempty"`
+  MaxElapsed *time.Duration `yaml:"max-elapsed,omitempty"`
+  Jitter *string `yaml:"jitter,omitempty"`
+}
+
+// ContextKeys lists the keys accepted by Context.Set.
+var ContextKeys = []string{
+  "address", "port", "socket", "transport", "cert", "key",
+  "ca", "client-cert", "client-key", "server-cert", "server-key", "client-auth", "insecure", "skip-verify", "source", "type",
+  "retry.enable", "retry.attempts", "retry.backoff", "retry.delay", "retry.max-delay", "retry.max-elapsed", "retry.jitter",
+}
+
+// DefaultConfigPath honours CECLI_CONFIG, then the user config directory.
+func DefaultConfigPath() string {
+  if path := os.Getenv("CECLI_CONFIG"); path != "" {
+    return path
+  }
+
+  dir, err := os.UserConfigDir()
+  if err != nil {
+    dir = filepath.Join(os.Getenv("HOME"), ".config")
+  }
+  return filepath.Join(dir, "cecli", "config.yaml")
+}
+
+// LoadConfigFile reads the config file, treating a missing file as empty.
+func LoadConfigFile(path string) (*ConfigFile, error) {
+  file := &ConfigFile{}
+
+  content, err := os.ReadFile(path)
+  if errors.Is(err, os.ErrNotExist) {
+    return file, nil
+  }
+  if err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+
+  if err := yaml.Unmarshal(content, file); err != nil {
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("%s: %v", path, err))
+  }
+  return file, nil
+}
+
+// Save writes the config file, readable only by its owner since contexts
+// point at private keys.
+func (file *ConfigFile) Save(path string) error {
+  var buffer bytes.Buffer
+  encoder := yaml.NewEncoder(&buffer)
+  encoder.SetIndent(2)
+  if err := encoder.Encode(file); err != nil {
+    return Wrap(ErrInvalidFormat, err)
+  }
+
+  if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+    return Wrap(ErrUnknown, err)
+  }
+  if err := os.WriteFile(path, buffer.Bytes(), 0o600); err != nil {
+    return Wrap(ErrUnknown, err)
+  }
+  return nil
+}
+
+// Context returns the named context, or the current one when name is empty.
+// It is nil when no context is selected.
+func (file *ConfigFile) Context(name string) (*Context, error) {
+  if name == "" {
+    name = file.CurrentContext
+  }
+  if name == "" {
+    return nil, nil
+  }
+
+  for i := range file.Contexts {
+    if file.Contexts[i].Name == name {
+      return &file.Contexts[i].Context, nil
+    }
+  }
+  return nil, Error(ErrInvalidFormat, fmt.Sprintf("context %q not found", name))
+}
+
+// UseContext makes name the current context.
+func (file *ConfigFile) UseContext(name string) error {
+  if _, err := file.Context(name); err != nil {
+    return err
+  }
+  file.CurrentContext = name
+  return nil
+}
+
+// Set changes a key of the named context, creating the context if needed.
+func (file *ConfigFile) Set(name string, key string, value string) error {
+  if name == "" {
+    return Error(ErrInvalidFormat, "no context given and no current context set")
+  }
+
+  context, err := file.Context(name)
+  if err != nil {
+    file.Contexts = append(file.Contexts, NamedContext{Name: name})
+    sort.Slice(file.Contexts, func(i, j int) bool { return file.Contexts[i].Name < file.Contexts[j].Name })
+    context, _ = file.Context(name)
+  }
+  return context.Set(key, value)
+}
+
+// Set changes one key of the context from its string form.
+func (context *Context) Set(key string, value string) error {
+  var err error
+
+  switch strings.ToLower(key) {
+  case "address":
+    context.Address = &value
+  case "port":
+    context.Port, err = parsed(strconv.Atoi(value))
+  case "socket":
+    context.Socket = &value
+  case "transport":
+    context.Transport = &value
+  case "cert":
+    context.Certificate = &value
+  case "key":
+    context.CertificateKey = &value
+  case "ca":
+    context.CA = &value
+  case "client-cert":
+    context.ClientCertificate = &value
+  case "client-key":
+    context.ClientKey = &value
+  case "server-cert":
+    context.ServerCertificate = &value
+  case "server-key":
+    context.ServerKey = &value
+  case "client-auth":
+    _, err = ParseClientAuth(value)
+    context.ClientAuth = &value
+  case "insecure":
+    context.Insecure, err = parsed(strconv.ParseBool(value))
+  case "skip-verify":
+    context.SkipVerify, err = parsed(strconv.ParseBool(value))
+  case "source":
+    context.Source = &value
+  case "type":
+    context.Type = &value
+  case "retry.enable":
+    context.Retry.Enable, err = parsed(strconv.ParseBool(value))
+  case "retry.attempts":
+    context.Retry.Attempts, err = parsed(strconv.Atoi(value))
+  case "retry.backoff":
+    context.Retry.Backoff = &value
+  case "retry.delay":
+    context.Retry.Delay, err = parsed(time.ParseDuration(value))
+  case "retry.max-delay":
+    context.Retry.MaxDelay, err = parsed(time.ParseDuration(value))
+  case "retry.max-elapsed":
+    context.Retry.MaxElapsed, err = parsed(time.ParseDuration(value))
+  case "retry.jitter":
+    context.Retry.Jitter = &value
+  default:
+    return Error(ErrInvalidFormat, fmt.Sprintf("unknown key %q, expected one of %s", key, strings.Join(ContextKeys, ", ")))
+  }
+
+  if err != nil {
+    return Wrap(ErrInvalidFormat, err, fmt.Sprintf("%s: %v", key, err))
+  }
+  return nil
+}
+
+// parsed points at a parsed value, or is nil when it did not parse.
+func parsed[T any](value T, err error) (*T, error) {
+  if err != nil {
+    return nil, err
+  }
+  return &value, nil
+}
+
+// Target describes where the context sends events, for listings.
+func (context *Context) Target() string {
+  if socket := ValueOf(context.Socket); socket != "" {
+    return "unix://" + socket
+  }
+  if context.Address == nil && context.Port == nil {
+    return ""
+  }
+  return fmt.Sprintf("%s:%d", ValueOf(context.Address), ValueOf(context.Port))
+}
+
+// ValueOf returns the value a context sets, or the zero value when unset.
+func ValueOf[T any](value *T) T {
+  if value == nil {
+    var zero T
+    return zero
+  }
+  return *value
+}
+
+// apply copies the values set in the context into config, except where the
+// matching CE_* variable is set since the environment takes precedence.
+func (context *Context) apply(config *CloudEventConfig) {
+  overlay(&config.Address, context.Address, "CE_ADDRESS")
+  overlay(&config.Port, context.Port, "CE_PORT")
+  overlay(&config.Socket, context.Socket, "CE_SOCKET")
+  overlay(&config.Protocol, context.Transport, "CE_TRANSPORT")
+  overlay(&config.Certificate, context.Certificate, "CE_CERT")
+  overlay(&config.CertificateKey, context.CertificateKey, "CE_KEY")
+  overlay(&config.CA, context.CA, "CE_CA")
+  overlay(&config.ClientCertificate, context.ClientCertificate, "CE_CLIENT_CERT")
+  overlay(&config.ClientKey, context.ClientKey, "CE_CLIENT_KEY")
+  overlay(&config.ServerCertificate, context.ServerCertificate, "CE_SERVER_CERT")
+  overlay(&config.ServerKey, context.ServerKey, "CE_SERVER_KEY")
+  overlay(&config.ClientAuth, context.ClientAuth, "CE_CLIENT_AUTH")
+  overlay(&config.Insecure, context.Insecure, "CE_INSECURE")
+  overlay(&config.SkipVerify, context.SkipVerify, "CE_SKIP_VERIFY")
+  overlay(&config.Source, context.Source, "CE_SOURCE")
+  overlay(&config.Type, context.Type, "CE_TYPE")
+
+  overlay(&config.Retry.Enable, context.Retry.Enable, "CE_RETRY")
+  overlay(&config.Retry.Attempts, context.Retry.Attempts, "CE_RETRY_ATTEMPTS")
+  overlay(&config.Retry.Backoff, context.Retry.Backoff, "CE_RETRY_BACKOFF")
+  overlay(&config.Retry.Delay, context.Retry.Delay, "CE_RETRY_DELAY")
+  overlay(&config.Retry.MaxDelay, context.Retry.MaxDelay, "CE_RETRY_MAX_DELAY")
+  overlay(&config.Retry.MaxElapsed, context.Retry.MaxElapsed, "CE_RETRY_MAX_ELAPSED")
+  overlay(&config.Retry.Jitter, context.Retry.Jitter, "CE_RETRY_JITTER")
+}
+
+// overlay sets target to value when the context sets it, even to its zero
+// value, and the variable named env does not.
+func overlay[T any](target *T, value *T, env string) {
+  if value == nil {
+    return
+  }
+  if _, ok := os.LookupEnv(env); ok {
+    return
+  }
+  *target = *value
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// unsetenv clears variables for the rest of the test.
+func unsetenv(t *testing.T, names ...string) {
+  t.Helper()
+  for _, name := range names {
+    t.Setenv(name, "")
+    _ = os.Unsetenv(name)
+  }
+}
+
+func TestContextKeepsExplicitZeroValuesThroughSave(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "config.yaml")
+
+  file := &ConfigFile{}
+  for key, value := range map[string]string{
+    "retry.enable": "false",
+    "retry.attempts": "0",
+    "retry.delay": "0s",
+    "skip-verify": "false",
+    "insecure": "false",
+    "source": "",
+  } {
+    if err := file.Set("staging", key, value); err != nil {
+      t.Fatalf("Set %s: %v", key, err)
+    }
+  }
+  if err := file.Save(path); err != nil {
+    t.Fatal(err)
+  }
+
+  saved, err := os.ReadFile(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  for _, line := range []string{"enable: false", "attempts: 0", "skip-verify: false", "insecure: false"} {
+    if !strings.Contains(string(saved), line) {
+      t.Errorf("saved config has no %q:\n%s", line, saved)
+    }
+  }
+
+  loaded, err := LoadConfigFile(path)
+  if err != nil {
+    t.Fatal(err)
+  }
+  context, err := loaded.Context("staging")
+  if err != nil {
+    t.Fatal(err)
+  }
+  if context.Retry.Enable == nil || *context.Retry.Enable || context.Retry.Attempts == nil || *context.Retry.Attempts != 0 {
+    t.Errorf("retry = %+v, want enable false and attempts 0 set", context.Retry)
+  }
+  if context.Retry.Delay == nil || *context.Retry.Delay != 0 {
+    t.Errorf("retry.delay = %v, want 0s set", context.Retry.Delay)
+  }
+  if context.SkipVerify == nil || context.Insecure == nil || context.Source == nil {
+    t.Errorf("skip-verify, insecure and source were not kept: %+v", context)
+  }
+  if context.Port != nil || context.Retry.Backoff != nil {
+    t.Errorf("keys that were never set are set: port %v, retry.backoff %v", context.Port, context.Retry.Backoff)
+  }
+}
+
+func TestContextPrecedence(t *testing.T) {
+  unsetenv(t, "CE_ADDRESS", "CE_PORT", "CE_SKIP_VERIFY", "CE_RETRY", "CE_RETRY_ATTEMPTS", "CE_RETRY_DELAY", "CE_RETRY_BACKOFF")
+
+  set := func(t *testing.T, pairs ...string) *Context {
+    t.Helper()
+    context := &Context{}
+    for index := 0; index < len(pairs); index += 2 {
+      if err := context.Set(pairs[index], pairs[index+1]); err != nil {
+        t.Fatal(err)
+      }
+    }
+    return context
+  }
+
+  tests := []struct {
+    name string
+    context []string
+    env map[string]string
+    check func(config *CloudEventConfig) bool
+    want string
+  }{
+    {
+      name: "default when the context leaves a key unset",
+      context: []string{"address", "events.example.com"},
+      check: func(config *CloudEventConfig) bool { return config.Port == 8080 && config.Retry.Attempts == 3 },
+      want: "port 8080 and 3 attempts",
+    },
+    {
+      name: "context over default",
+      context: []string{"port", "8443", "retry.backoff", "exponential"},
+      check: func(config *CloudEventConfig) bool { return config.Port == 8443 && config.Retry.Backoff == "exponential" },
+      want: "port 8443 and exponential backoff",
+    },
+    {
+      name: "context zero values over default",
+      context: []string{"port", "0", "retry.attempts", "0", "retry.delay", "0s", "retry.backoff", ""},
+      check: func(config *CloudEventConfig) bool {
+        return config.Port == 0 && config.Retry.Attempts == 0 && config.Retry.Delay == 0 && config.Retry.Backoff == ""
+      },
+      want: "port 0, 0 attempts, no delay and no backoff",
+    },
+    {
+      name: "environment over context",
+      context: []string{"skip-verify", "false", "retry.enable", "false", "retry.attempts", "0"},
+      env: map[string]string{"CE_SKIP_VERIFY": "true", "CE_RETRY": "true", "CE_RETRY_ATTEMPTS": "5"},
+      check: func(config *CloudEventConfig) bool { return config.SkipVerify && config.Retry.Enable && config.Retry.Attempts == 5 },
+      want: "skip-verify, retries and 5 attempts",
+    },
+    {
+      name: "environment zero value over context",
+      context: []string{"retry.enable", "true", "retry.delay", "5s"},
+      env: map[string]string{"CE_RETRY": "false", "CE_RETRY_DELAY": "0s"},
+      check: func(config *CloudEventConfig) bool { return !config.Retry.Enable && config.Retry.Delay == 0 },
+      want: "no retries and no delay",
+    },
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      for name, value := range test.env {
+        t.Setenv(name, value)
+      }
+
+      config, err := LoadConfig(set(t, test.context...))
+      if err != nil {
+        t.Fatal(err)
+      }
+      if !test.check(config) {
+        t.Errorf("got port %d, skip-verify %t, retry %+v, want %s", config.Port, config.SkipVerify, config.Retry, test.want)
+      }
+    })
+  }
+}
+
+func TestContextApplySetsFalse(t *testing.T) {
+  unsetenv(t, "CE_INSECURE", "CE_SKIP_VERIFY", "CE_RETRY")
+
+  context := &Context{}
+  for _, key := range []string{"insecure", "skip-verify", "retry.enable"} {
+    if err := context.Set(key, "false"); err != nil {
+      t.Fatal(err)
+    }
+  }
+
+  config := &CloudEventConfig{Insecure: true, SkipVerify: true, Retry: RetryConfig{Enable: true}}
+  context.apply(config)
+  if config.Insecure || config.SkipVerify || config.Retry.Enable {
+    t.Errorf("insecure %t, skip-verify %t, retry %t after applying false", config.Insecure, config.SkipVerify, config.Retry.Enable)
+  }
+}
+
+func TestContextSetRejectsValuesThatDoNotParse(t *testing.T) {
+  for key, value := range map[string]string{"port": "eighty", "retry.enable": "maybe", "retry.delay": "soon", "client-auth": "sometimes"} {
+    context := &Context{}
+    if err := context.Set(key, value); err == nil {
+      t.Errorf("Set %s %q succeeded", key, value)
+    }
+  }
+
+  context := &Context{}
+  if err := context.Set("retry.delay", "250ms"); err != nil || context.Retry.Delay == nil || *context.Retry.Delay != 250*time.Millisecond {
+    t.Errorf("retry.delay = %v, %v, want 250ms", context.Retry.Delay, err)
+  }
+}
@@ -0,0 +1,449 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+	"github.com/cloudevents/sdk-go/v2/types"
+	"github.com/hamba/avro/v2/ocf"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+const (
+  EncodingStructuredJSON = "structured-json"
+  EncodingBinaryHTTP = "binary-http"
+  EncodingProtobuf = "protobuf"
+  EncodingAvro = "avro"
+  EncodingBatch = "batch"
+)
+
+// encodingInputs are the inputs ReadRawEvents detects for each encoding
+// events can be converted from.
+var encodingInputs = map[string][]string{
+  EncodingStructuredJSON: {InputStructured, InputJSONL, InputStructuredHTTP},
+  EncodingBatch: {InputBatch, InputBatchHTTP},
+  EncodingBinaryHTTP: {InputBinaryHTTP},
+}
+
+// ReadEncodedEvents parses events in the given encoding, rejecting input that
+// turns out to use another one.
+func ReadEncodedEvents(content []byte, encoding string) ([]cloudevents.Event, error) {
+  inputs, ok := encodingInputs[strings.ToLower(encoding)]
+  if !ok {
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("cannot convert from %q (structured-json, batch, binary-http)", encoding))
+  }
+
+  raws, input, err := ReadRawEvents(content)
+  if err != nil {
+    return nil, err
+  }
+  if !contains(inputs, input) {
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("input is %s, not %s", input, encoding))
+  }
+
+  events := make([]cloudevents.Event, 0, len(raws))
+  for index, raw := range raws {
+    event, err := raw.Event()
+    if err != nil {
+      return nil, Wrap(ErrInvalidFormat, err, fmt.Sprintf("event %d: %s", index, Message(err)))
+    }
+    events = append(events, event)
+  }
+  return events, nil
+}
+
+func contains(values []string, value string) bool {
+  for _, candidate := range values {
+    if candidate == value {
+      return true
+    }
+  }
+  return false
+}
+
+// MARK: - HTTP
+
+// BinaryRequest builds the request delivering the event to target in binary mode.
+func BinaryRequest(ctx context.Context, target string, event cloudevents.Event) (*http.Request, error) {
+  req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, nil)
+  if err != nil {
+    return nil, Wrap(ErrInvalidURL, err)
+  }
+  if err := cehttp.WriteRequest(binding.WithForceBinary(ctx), (*binding.EventMessage)(&event), req); err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+  return req, nil
+}
+
+// BatchRequest builds the request delivering the events to target in batch mode.
+func BatchRequest(ctx context.Context, target string, events []cloudevents.Event) (*http.Request, error) {
+  req, err := cehttp.NewHTTPRequestFromEvents(ctx, target, events)
+  if err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+  return req, nil
+}
+
+// DumpRequest renders a request as it goes on the wire, headers and body.
+// Content-Length is always set, even to 0, so dumps can be read back one
+// after another.
+func DumpRequest(req *http.Request) ([]byte, error) {
+  body, err := requestBody(req)
+  if err != nil {
+    return nil, err
+  }
+  if req.Header.Get("Content-Length") == "" {
+    req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+  }
+
+  dump, err := httputil.DumpRequest(req, true)
+  if err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+  return dump, nil
+}
+
+// CurlCommand renders a request as a curl command. Bodies that are not text
+// are piped in base64 encoded, so the command stays printable.
+func CurlCommand(req *http.Request, options ...string) (string, error) {
+  body, err := requestBody(req)
+  if err != nil {
+    return "", err
+  }
+
+  names := make([]string, 0, len(req.Header))
+  for name := range req.Header {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+
+  lines := []string{fmt.Sprintf("curl -X %s %s", req.Method, ShellQuote(req.URL.String()))}
+  for _, name := range names {
+    for _, value := range req.Header[name] {
+      lines = append(lines, fmt.Sprintf("  -H %s", doubleQuote(name+": "+value)))
+    }
+  }
+  lines = append(lines, options...)
+
+  prefix := ""
+  switch {
+  case len(body) == 0:
+  case utf8.Valid(body):
+    lines = append(lines, fmt.Sprintf("  -d %s", ShellQuote(strings.TrimSuffix(string(body), "\n"))))
+  default:
+    prefix = fmt.Sprintf("echo %s | base64 -d | ", base64.StdEncoding.EncodeToString(body))
+    lines = append(lines, "  --data-binary @-")
+  }
+  return prefix + strings.Join(lines, " \\\n"), nil
+}
+
+// requestBody reads the body, leaving the request able to be sent.
+func requestBody(req *http.Request) ([]byte, error) {
+  if req.Body == nil {
+    return nil, nil
+  }
+  body, err := io.ReadAll(req.Body)
+  if err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+  req.Body = io.NopCloser(bytes.NewReader(body))
+  return body, nil
+}
+
+// ShellQuote single quotes a value for a POSIX shell.
+func ShellQuote(value string) string {
+  return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func doubleQuote(value string) string {
+  return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(value) + `"`
+}
+
+// MARK: - Protobuf
+
+// EncodeProtobuf encodes one event as application/cloudevents+protobuf.
+// Several events are written length delimited, each prefixed by its size as
+// a varint, as the protobuf format has no batch message.
+func EncodeProtobuf(w io.Writer, events []cloudevents.Event) error {
+  if len(events) == 1 {
+    encoded, err := protobuf.Protobuf.Marshal(&events[0])
+    if err != nil {
+      return Wrap(ErrInvalidFormat, err)
+    }
+    _, err = w.Write(encoded)
+    return err
+  }
+
+  for _, event := range events {
+    message, err := protobuf.ToProto(&event)
+    if err != nil {
+      return Wrap(ErrInvalidFormat, err)
+    }
+    if _, err := protodelim.MarshalTo(w, message); err != nil {
+      return Wrap(ErrInvalidFormat, err)
+    }
+  }
+  return nil
+}
+
+// MARK: - Avro
+
+// avroSchema is the Avro event format schema of CloudEvents 1.0.
+const avroSchema = `{
+  "namespace": "io.cloudevents",
+  "type": "record",
+  "name": "CloudEvent",
+  "version": "1.0",
+  "doc": "Avro Event Format for CloudEvents",
+  "fields": [
+    {
+      "name": "attribute",
+      "type": {"type": "map", "values": ["null", "boolean", "int", "string", "bytes"]}
+    },
+    {
+      "name": "data",
+      "type": [
+        "bytes",
+        "null",
+        "boolean",
+        {
+          "type": "map",
+          "values": [
+            "null",
+            "boolean",
+            {
+              "type": "record",
+              "name": "CloudEventData",
+              "doc": "Representation of a JSON Value",
+              "fields": [
+                {
+                  "name": "value",
+                  "type": {
+                    "type": "map",
+                    "values": [
+                      "null",
+                      "boolean",
+                      {"type": "map", "values": "CloudEventData"},
+                      {"type": "array", "items": "CloudEventData"},
+                      "double",
+                      "string"
+                    ]
+                  }
+                }
+              ]
+            },
+            "double",
+            "string"
+          ]
+        },
+        {"type": "array", "items": "CloudEventData"},
+        "double",
+        "string"
+      ]
+    }
+  ]
+}`
+
+type avroEvent struct {
+  Attribute map[string]any `avro:"attribute"`
+  Data any `avro:"data"`
+}
+
+// avroDataName is the CloudEventData record the Avro format nests JSON
+// values in.
+const avroDataName = "io.cloudevents.CloudEventData"
+
+type avroData struct {
+  Value map[string]any `avro:"value"`
+}
+
+// EncodeAvro writes the events to an Avro object container file using the
+// CloudEvents Avro format. JSON data is stored as a JSON value, other textual
+// data as a string and the rest as bytes.
+func EncodeAvro(w io.Writer, events []cloudevents.Event) error {
+  encoder, err := ocf.NewEncoder(avroSchema, w)
+  if err != nil {
+    return Wrap(ErrInvalidFormat, err)
+  }
+
+  for _, event := range events {
+    record := avroEvent{Attribute: map[string]any{
+      "specversion": event.SpecVersion(),
+      "id":          event.ID(),
+      "source":      event.Source(),
+      "type":        event.Type(),
+    }}
+    optional := map[string]string{
+      "datacontenttype": event.DataContentType(),
+      "dataschema":      event.DataSchema(),
+      "subject":         event.Subject(),
+    }
+    for name, value := range optional {
+      if value != "" {
+        record.Attribute[name] = value
+      }
+    }
+    if !event.Time().IsZero() {
+      record.Attribute["time"] = types.FormatTime(event.Time())
+    }
+    for name, value := range event.Extensions() {
+      record.Attribute[name] = avroValue(value)
+    }
+
+    switch data := event.Data(); {
+    case data == nil:
+      record.Data = nil
+    case event.DataContentType() == "" || isJSON(event.DataContentType()):
+      var value any
+      if err := json.Unmarshal(data, &value); err != nil {
+        return Wrap(ErrInvalidFormat, err)
+      }
+      record.Data = avroJSON(value)
+    case isText(event.DataContentType()):
+      record.Data = string(data)
+    default:
+      record.Data = data
+    }
+
+    if err := encoder.Encode(record); err != nil {
+      return Wrap(ErrInvalidFormat, err)
+    }
+  }
+
+  if err := encoder.Close(); err != nil {
+    return Wrap(ErrInvalidFormat, err)
+  }
+  return nil
+}
+
+// avroJSON maps a JSON value onto the data union. Objects become maps and
+// arrays hold CloudEventData records, as do nested objects and arrays.
+func avroJSON(value any) any {
+  switch typed := value.(type) {
+  case map[string]any:
+    members := make(map[string]any, len(typed))
+    for name, member := range typed {
+      switch member.(type) {
+      case map[string]any, []any:
+        members[name] = avroUnion(avroDataName, avroRecord(member))
+      default:
+        members[name] = avroScalar(member)
+      }
+    }
+    return avroUnion("map", members)
+  case []any:
+    return avroUnion("array", avroRecords(typed))
+  default:
+    return avroScalar(typed)
+  }
+}
+
+// avroRecord wraps a JSON value in a CloudEventData record. Objects keep
+// their members, other values are held under "value".
+func avroRecord(value any) avroData {
+  if object, ok := value.(map[string]any); ok {
+    members := make(map[string]any, len(object))
+    for name, member := range object {
+      members[name] = avroNested(member)
+    }
+    return avroData{Value: members}
+  }
+  return avroData{Value: map[string]any{"value": avroNested(value)}}
+}
+
+// avroNested maps a JSON value held inside a CloudEventData record.
+func avroNested(value any) any {
+  switch typed := value.(type) {
+  case map[string]any:
+    members := make(map[string]avroData, len(typed))
+    for name, member := range typed {
+      members[name] = avroRecord(member)
+    }
+    return avroUnion("map", members)
+  case []any:
+    return avroUnion("array", avroRecords(typed))
+  default:
+    return avroScalar(typed)
+  }
+}
+
+func avroRecords(values []any) []avroData {
+  records := make([]avroData, len(values))
+  for i, value := range values {
+    records[i] = avroRecord(value)
+  }
+  return records
+}
+
+// avroScalar names the union branch of a JSON null, boolean, number or string.
+func avroScalar(value any) any {
+  switch typed := value.(type) {
+  case bool:
+    return avroUnion("boolean", typed)
+  case float64:
+    return avroUnion("double", typed)
+  case string:
+    return avroUnion("string", typed)
+  default:
+    return nil
+  }
+}
+
+// avroUnion selects a union branch by name, since a map would otherwise be
+// read as the branch selection itself.
+func avroUnion(name string, value any) map[string]any {
+  return map[string]any{name: value}
+}
+
+// avroValue keeps the extension types the Avro format has, formatting the
+// others as strings.
+func avroValue(value any) any {
+  switch typed := value.(type) {
+  case bool, int32, string, []byte:
+    return typed
+  default:
+    formatted, err := types.Format(value)
+    if err != nil {
+      return fmt.Sprint(value)
+    }
+    return formatted
+  }
+}
+
+// MARK: - JSON
+
+// EncodeBatch writes the events as a JSON batch.
+func EncodeBatch(w io.Writer, events []cloudevents.Event) error {
+  if err := json.NewEncoder(w).Encode(events); err != nil {
+    return Wrap(ErrInvalidFormat, err)
+  }
+  return nil
+}
+
+// EncodeJSON writes each event as a structured JSON event on its own line.
+func EncodeJSON(w io.Writer, events []cloudevents.Event) error {
+  encoder := json.NewEncoder(w)
+  for _, event := range events {
+    if err := encoder.Encode(event); err != nil {
+      return Wrap(ErrInvalidFormat, err)
+    }
+  }
+  return nil
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"strconv"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/hamba/avro/v2/ocf"
+)
+
+func TestEncodeAvroMapsJSONDataOntoTheUnion(t *testing.T) {
+  event := cloudevents.NewEvent()
+  event.SetID("order-42")
+  event.SetSource("/orders")
+  event.SetType("com.example.order")
+  if err := event.SetData(cloudevents.ApplicationJSON, map[string]any{
+    "id":     "42",
+    "amount": 10,
+    "paid":   true,
+    "note":   nil,
+    "items":  []any{"book"},
+  }); err != nil {
+    t.Fatal(err)
+  }
+
+  var encoded bytes.Buffer
+  if err := EncodeAvro(&encoded, []cloudevents.Event{event}); err != nil {
+    t.Fatalf("EncodeAvro: %v", err)
+  }
+
+  decoder, err := ocf.NewDecoder(&encoded)
+  if err != nil {
+    t.Fatal(err)
+  }
+  var record map[string]any
+  if !decoder.HasNext() {
+    t.Fatal("no record was written")
+  }
+  if err := decoder.Decode(&record); err != nil {
+    t.Fatal(err)
+  }
+
+  wrapped := func(value any) map[string]any {
+    return map[string]any{"value": map[string]any{"value": value}}
+  }
+  want := map[string]any{"map": map[string]any{
+    "id":     map[string]any{"string": "42"},
+    "amount": map[string]any{"double": float64(10)},
+    "paid":   map[string]any{"boolean": true},
+    "note":   nil,
+    "items": map[string]any{avroDataName: wrapped(map[string]any{
+      "array": []any{wrapped(map[string]any{"string": "book"})},
+    })},
+  }}
+  if !reflect.DeepEqual(record["data"], want) {
+    t.Errorf("data = %#v\nwant %#v", record["data"], want)
+  }
+}
+
+func TestCurlCommandQuotesTheURL(t *testing.T) {
+  req, err := http.NewRequest(http.MethodPost, "https://localhost:8443/events?tenant=acme&region=eu", nil)
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  command, err := CurlCommand(req, "  --cacert "+ShellQuote("/etc/my ca's.pem"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  want := `curl -X POST 'https://localhost:8443/events?tenant=acme&region=eu' \` + "\n" + `  --cacert '/etc/my ca'\''s.pem'`
+  if command != want {
+    t.Errorf("command = %s\nwant %s", command, want)
+  }
+}
+
+func TestBinaryRequestDumpsRoundTrip(t *testing.T) {
+  events := make([]cloudevents.Event, 3)
+  for index := range events {
+    events[index] = cloudevents.NewEvent()
+    events[index].SetID(strconv.Itoa(index))
+    events[index].SetSource("/orders")
+    events[index].SetType("com.example.order")
+  }
+  if err := events[0].SetData(cloudevents.ApplicationJSON, map[string]any{"order": 1}); err != nil {
+    t.Fatal(err)
+  }
+  if err := events[2].SetData(cloudevents.TextPlain, "last"); err != nil {
+    t.Fatal(err)
+  }
+
+  // As convert writes them, one dump after another separated by a blank line
+  var dumps bytes.Buffer
+  for index, event := range events {
+    req, err := BinaryRequest(t.Context(), "http://localhost:8080", event)
+    if err != nil {
+      t.Fatal(err)
+    }
+    dump, err := DumpRequest(req)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if !bytes.Contains(dump, []byte("Content-Length: ")) {
+      t.Errorf("dump of event %d has no Content-Length:\n%s", index, dump)
+    }
+    if index > 0 {
+      dumps.WriteString("\n")
+    }
+    dumps.Write(dump)
+  }
+
+  decoded, err := ReadEncodedEvents(dumps.Bytes(), EncodingBinaryHTTP)
+  if err != nil {
+    t.Fatalf("ReadEncodedEvents: %v\n%s", err, dumps.Bytes())
+  }
+  if len(decoded) != len(events) {
+    t.Fatalf("read %d events, want %d", len(decoded), len(events))
+  }
+  for index, event := range decoded {
+    if event.ID() != events[index].ID() {
+      t.Errorf("event %d: id = %q, want %q", index, event.ID(), events[index].ID())
+    }
+    if !bytes.Equal(event.Data(), events[index].Data()) {
+      t.Errorf("event %d: data = %q, want %q", index, event.Data(), events[index].Data())
+    }
+  }
+}
@@ -4,6 +4,7 @@
 package cloudevent
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -19,18 +20,25 @@ const (
 
 type CloudEventErrorCodes int
 
+// CloudEventError carries one of the codes above, which the CLI also uses as
+// its exit code, and the error that caused it, if any.
 type CloudEventError struct {
   Code    CloudEventErrorCodes `json:"code"`
   Message string `json:"message"`
+  Cause error `json:"-"`
 }
 
-func (err *CloudEventError) Error() error {
-  return fmt.Errorf("CloudEventError - %d: %s", err.Code, err.Message)
+func (err *CloudEventError) Error() string {
+  return fmt.Sprintf("CloudEventError - %d: %s", err.Code, err.Message)
 }
 
-func Error(code CloudEventErrorCodes, message ...string) error {
-  err := CloudEventError{}
-  err.Code = code
+func (err *CloudEventError) Unwrap() error { return err.Cause }
+
+// Error returns a *CloudEventError rather than an error so callers can read
+// its Code. Code that only holds an error should find it with errors.As,
+// since it may be wrapped, as SchemaError and Wrap do.
+func Error(code CloudEventErrorCodes, message ...string) *CloudEventError {
+  err := &CloudEventError{Code: code}
 
   if len(message) == 0 {
     err.Message = "An unknown error occurred"
@@ -38,5 +46,27 @@ func Error(code CloudEventErrorCodes, message ...string) error {
     err.Message = message[0]
   }
 
+  return err
+}
+
+// Wrap returns an error with the given code caused by cause, described by
+// message or else by the cause itself.
+func Wrap(code CloudEventErrorCodes, cause error, message ...string) *CloudEventError {
+  if len(message) == 0 {
+    message = []string{cause.Error()}
+  }
+  err := Error(code, message...)
+  err.Cause = cause
+  return err
+}
+
+// Message returns the message of err without the code prefix a
+// CloudEventError adds, so wrapping it again with more context does not
+// repeat the prefix.
+func Message(err error) string {
+  var cloudEventErr *CloudEventError
+  if errors.As(err, &cloudEventErr) {
+    return cloudEventErr.Message
+  }
   return err.Error()
 }
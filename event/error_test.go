@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestWrapKeepsCodeAndCause(t *testing.T) {
+  var err error = Wrap(ErrSendFailed, io.ErrUnexpectedEOF)
+
+  var failure *CloudEventError
+  if !errors.As(err, &failure) {
+    t.Fatalf("errors.As found no CloudEventError in %v", err)
+  }
+  if failure.Code != ErrSendFailed {
+    t.Errorf("code = %d, want %d", failure.Code, ErrSendFailed)
+  }
+  if !errors.Is(err, io.ErrUnexpectedEOF) {
+    t.Errorf("errors.Is lost the cause of %v", err)
+  }
+  if want := "CloudEventError - 5: unexpected EOF"; err.Error() != want {
+    t.Errorf("message = %q, want %q", err.Error(), want)
+  }
+}
+
+func TestErrorDefaultsMessage(t *testing.T) {
+  err := Error(ErrUnknown)
+  if err.Message != "An unknown error occurred" || err.Unwrap() != nil {
+    t.Errorf("unexpected error %#v", err)
+  }
+}
+
+func TestSchemaErrorCarriesItsCode(t *testing.T) {
+  var err error = &SchemaError{Schema: "order.json", Violations: []Violation{{Location: "/id", Message: "is required"}}}
+  err = fmt.Errorf("validate: %w", err)
+
+  var failure *CloudEventError
+  if !errors.As(err, &failure) {
+    t.Fatalf("errors.As found no CloudEventError in %v", err)
+  }
+  if failure.Code != ErrInvalidFormat {
+    t.Errorf("code = %d, want %d", failure.Code, ErrInvalidFormat)
+  }
+
+  var schemaErr *SchemaError
+  if !errors.As(err, &schemaErr) || len(schemaErr.Violations) != 1 {
+    t.Errorf("errors.As lost the violations of %v", err)
+  }
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"fmt"
+	"strings"
+
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+  FormatJSON = "json"
+  FormatProtobuf = "protobuf"
+)
+
+// ParseFormat maps a format name to the event format used for structured mode.
+func ParseFormat(name string) (format.Format, error) {
+  switch strings.ToLower(name) {
+  case "", FormatJSON:
+    return format.JSON, nil
+  case FormatProtobuf:
+    return protobuf.Protobuf, nil
+  default:
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("unknown event format %q", name))
+  }
+}
+
+// IsProtobuf reports whether events are encoded as application/cloudevents+protobuf.
+func IsProtobuf(f format.Format) bool {
+  return f != nil && f.MediaType() == protobuf.ApplicationCloudEventsProtobuf
+}
+
+// typeUrl names a message the way google.protobuf.Any does, so the data can be
+// carried as proto_data. The scheme keeps it a valid absolute dataschema.
+func typeUrl(message proto.Message) string {
+  return "https://type.googleapis.com/" + string(message.ProtoReflect().Descriptor().FullName())
+}
@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	api "github.com/anselmes/ce-go-template/api/v1"
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+	"github.com/cloudevents/sdk-go/binding/format/protobuf/v2/pb"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// GrpcClient is a cloudevents.Client that publishes events to an EventService
+// and receives them by subscribing to it.
+type GrpcClient struct {
+  conn *grpc.ClientConn
+  service api.EventServiceClient
+}
+
+func NewGrpcClient(conn *grpc.ClientConn) *GrpcClient {
+  return &GrpcClient{conn: conn, service: api.NewEventServiceClient(conn)}
+}
+
+func (client *GrpcClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+  message, err := protobuf.ToProto(&event)
+  if err != nil {
+    return Wrap(ErrInvalidFormat, err)
+  }
+
+  _, err = client.service.Publish(ctx, &api.PublishRequest{Event: message})
+  return grpcResult(err)
+}
+
+// SendBatch publishes all events in a single PublishBatch call.
+func (client *GrpcClient) SendBatch(ctx context.Context, events []cloudevents.Event) protocol.Result {
+  request := &api.PublishBatchRequest{}
+  for _, event := range events {
+    message, err := protobuf.ToProto(&event)
+    if err != nil {
+      return Wrap(ErrInvalidFormat, err)
+    }
+    request.Events = append(request.Events, message)
+  }
+
+  response, err := client.service.PublishBatch(ctx, request)
+  if err != nil {
+    return grpcResult(err)
+  }
+
+  results := make([]BatchResult, 0, len(response.Results))
+  failed := false
+  for _, result := range response.Results {
+    results = append(results, BatchResult{ID: result.Id, Status: int(result.Status), Error: result.Error})
+    failed = failed || result.Status/100 != 2
+  }
+
+  if failed {
+    return protocol.NewReceipt(false, "%s", summarize(results))
+  }
+  return protocol.ResultACK
+}
+
+// Close closes the connection to the service.
+func (client *GrpcClient) Close(ctx context.Context) error {
+  return client.conn.Close()
+}
+
+func (client *GrpcClient) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+  return nil, client.Send(ctx, event)
+}
+
+// StartReceiver subscribes to the service and invokes fn for every streamed
+// event until ctx is done.
+func (client *GrpcClient) StartReceiver(ctx context.Context, fn interface{}) error {
+  handler, ok := fn.(func(context.Context, cloudevents.Event) error)
+  if !ok {
+    return Error(ErrReceiveFailed, fmt.Sprintf("unsupported receiver %T", fn))
+  }
+
+  stream, err := client.service.Subscribe(ctx, &api.SubscribeRequest{})
+  if err != nil {
+    return Wrap(ErrReceiveFailed, err)
+  }
+
+  for {
+    message, err := stream.Recv()
+    if errors.Is(err, io.EOF) || ctx.Err() != nil {
+      return nil
+    }
+    if err != nil {
+      return Wrap(ErrReceiveFailed, err)
+    }
+
+    event, err := protobuf.FromProto(message)
+    if err != nil {
+      log.Printf("Dropping malformed CloudEvent from stream: %v", err)
+      continue
+    }
+    if err := handler(ctx, *event); err != nil {
+      log.Printf("Callback failed for CloudEvent %s: %v", event.ID(), err)
+    }
+  }
+}
+
+// grpcResult maps a call error to a protocol result. Connectivity problems are
+// reported as plain errors so they count as undelivered, not rejected.
+func grpcResult(err error) protocol.Result {
+  if err == nil {
+    return protocol.ResultACK
+  }
+
+  switch status.Code(err) {
+  case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+    return err
+  default:
+    return protocol.NewReceipt(false, "%s", status.Convert(err).Message())
+  }
+}
+
+func (config CloudEventConfig) grpcClient(ctx context.Context) (cloudevents.Client, error) {
+  target := config.Url().Host
+  creds := insecure.NewCredentials()
+  if config.IsSocket() {
+    target = config.Endpoint()
+  } else if !config.Insecure {
+    if err := config.loadTLS(ctx, hostOf(target)); err != nil {
+      return nil, err
+    }
+    creds = credentials.NewTLS(config.Config)
+  }
+
+  conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(creds))
+  if err != nil {
+    return nil, Wrap(ErrInvalidURL, err)
+  }
+  return NewGrpcClient(conn), nil
+}
+
+// MARK: - Server
+
+// EventServer implements the EventService, handing published events to the
+// manager callback and fanning accepted ones out to subscribers.
+type EventServer struct {
+  manager *CloudEventManager
+  mutex sync.Mutex
+  subscribers map[chan *pb.CloudEvent]*api.SubscribeRequest
+}
+
+func NewEventServer(manager *CloudEventManager) *EventServer {
+  return &EventServer{manager: manager, subscribers: map[chan *pb.CloudEvent]*api.SubscribeRequest{}}
+}
+
+func (server *EventServer) Publish(ctx context.Context, request *api.PublishRequest) (*api.PublishResponse, error) {
+  if err := server.publish(ctx, request.Event); err != nil {
+    return nil, err
+  }
+  return &api.PublishResponse{}, nil
+}
+
+func (server *EventServer) PublishBatch(ctx context.Context, request *api.PublishBatchRequest) (*api.PublishBatchResponse, error) {
+  response := &api.PublishBatchResponse{}
+
+  for _, message := range request.Events {
+    result := &api.EventResult{Id: message.GetId(), Status: http.StatusOK}
+    if err := server.publish(ctx, message); err != nil {
+      result.Status = http.StatusInternalServerError
+      if status.Code(err) == codes.InvalidArgument {
+        result.Status = http.StatusBadRequest
+      }
+      result.Error = status.Convert(err).Message()
+    }
+    response.Results = append(response.Results, result)
+  }
+  return response, nil
+}
+
+func (server *EventServer) Subscribe(request *api.SubscribeRequest, stream grpc.ServerStreamingServer[pb.CloudEvent]) error {
+  events := make(chan *pb.CloudEvent, 64)
+
+  server.mutex.Lock()
+  server.subscribers[events] = request
+  server.mutex.Unlock()
+
+  defer func() {
+    server.mutex.Lock()
+    delete(server.subscribers, events)
+    server.mutex.Unlock()
+  }()
+
+  for {
+    select {
+    case <-stream.Context().Done():
+      return nil
+    case message := <-events:
+      if err := stream.Send(message); err != nil {
+        return err
+      }
+    }
+  }
+}
+
+func (server *EventServer) publish(ctx context.Context, message *pb.CloudEvent) error {
+  if caller, ok := peer.FromContext(ctx); ok {
+    if info, ok := caller.AuthInfo.(credentials.TLSInfo); ok {
+      ctx = withPeer(ctx, &info.State)
+    }
+  }
+
+  if message == nil {
+    return status.Error(codes.InvalidArgument, "missing event")
+  }
+
+  event, err := protobuf.FromProto(message)
+  if err != nil {
+    return status.Error(codes.InvalidArgument, err.Error())
+  }
+  if err := event.Validate(); err != nil {
+    return status.Error(codes.InvalidArgument, err.Error())
+  }
+
+  ctx, err = server.manager.accept(ctx, *event)
+  if err != nil {
+    return status.Error(codes.InvalidArgument, err.Error())
+  }
+  if err := server.manager.handle(ctx, *event); err != nil {
+    return status.Error(codes.Internal, err.Error())
+  }
+
+  server.broadcast(message)
+  return nil
+}
+
+// broadcast forwards an event to matching subscribers, dropping it for any
+// subscriber that is not keeping up.
+func (server *EventServer) broadcast(message *pb.CloudEvent) {
+  server.mutex.Lock()
+  defer server.mutex.Unlock()
+
+  for events, filter := range server.subscribers {
+    if !strings.HasPrefix(message.Type, filter.Type) || !strings.HasPrefix(message.Source, filter.Source) {
+      continue
+    }
+
+    select {
+    case events <- message:
+    default:
+      log.Printf("Subscriber is too slow, dropping CloudEvent %s", message.Id)
+    }
+  }
+}
+
+// ListenGRPC serves the EventService until ctx is done, invoking callback for
+// every published event.
+func (manager *CloudEventManager) ListenGRPC(ctx context.Context, config *CloudEventConfig, callback callback) error {
+  manager.SetCallback(callback)
+
+  creds := insecure.NewCredentials()
+  if !config.Insecure && !config.IsSocket() {
+    // Load TLS configuration
+    tlsConfig, err := config.ServerTLS(ctx)
+    if err != nil {
+      return err
+    }
+    creds = credentials.NewTLS(tlsConfig)
+  }
+
+  listener, err := config.Listener()
+  if err != nil {
+    return err
+  }
+
+  server := grpc.NewServer(grpc.Creds(creds))
+  api.RegisterEventServiceServer(server, NewEventServer(manager))
+
+  log.Printf("Serving CloudEvent gRPC service on %s...", config.Endpoint())
+
+  return ServeGRPC(ctx, server, listener, config.ShutdownTimeout)
+}
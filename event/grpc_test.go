@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"google.golang.org/grpc/connectivity"
+
+	api "github.com/anselmes/ce-go-template/api/v1"
+)
+
+func TestGrpcClientCloseReleasesTheConnection(t *testing.T) {
+  config := &CloudEventConfig{Protocol: ProtocolGRPC, Socket: filepath.Join(t.TempDir(), "events.sock")}
+
+  received := make(chan cloudevents.Event, 1)
+  ctx, cancel := context.WithCancel(context.Background())
+  done := make(chan error, 1)
+  go func() {
+    done <- NewCloudEventManager(&api.Data{}, nil).ListenGRPC(ctx, config, func(ctx context.Context, event cloudevents.Event) error {
+      received <- event
+      return nil
+    })
+  }()
+  t.Cleanup(func() {
+    cancel()
+    <-done
+  })
+  waitFor(t, "the socket", func() bool {
+    _, err := os.Stat(config.Socket)
+    return err == nil
+  })
+
+  client, err := config.Client(t.Context())
+  if err != nil {
+    t.Fatal(err)
+  }
+  manager := NewCloudEventManager(&api.Data{Message: "created"}, &CloudEventOptions{Source: "/orders", Type: "com.example.order", ID: "order-1"})
+  manager.SetRetry(1)
+  if _, err := manager.Send(context.Background(), client); err != nil {
+    t.Fatalf("Send: %v", err)
+  }
+  select {
+  case event := <-received:
+    if event.ID() != "order-1" {
+      t.Errorf("received %s, want order-1", event.ID())
+    }
+  case <-time.After(10 * time.Second):
+    t.Fatal("no event received")
+  }
+
+  if err := Close(context.Background(), client); err != nil {
+    t.Fatalf("Close: %v", err)
+  }
+  if state := client.(*GrpcClient).conn.GetState(); state != connectivity.Shutdown {
+    t.Errorf("connection is %s after Close, want %s", state, connectivity.Shutdown)
+  }
+}
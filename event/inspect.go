@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"time"
+)
+
+var keyUsageNames = []struct {
+  usage x509.KeyUsage
+  name string
+}{
+  {x509.KeyUsageDigitalSignature, "digital signature"},
+  {x509.KeyUsageContentCommitment, "content commitment"},
+  {x509.KeyUsageKeyEncipherment, "key encipherment"},
+  {x509.KeyUsageKeyAgreement, "key agreement"},
+  {x509.KeyUsageDataEncipherment, "data encipherment"},
+  {x509.KeyUsageCertSign, "cert sign"},
+  {x509.KeyUsageCRLSign, "crl sign"},
+  {x509.KeyUsageEncipherOnly, "encipher only"},
+  {x509.KeyUsageDecipherOnly, "decipher only"},
+}
+
+// Usages names the key usages and extended key usages of cert using the
+// cert.yaml spelling.
+func Usages(cert *x509.Certificate) []string {
+  var names []string
+  for _, entry := range keyUsageNames {
+    if cert.KeyUsage&entry.usage != 0 {
+      names = append(names, entry.name)
+    }
+  }
+
+  for _, usage := range cert.ExtKeyUsage {
+    name := fmt.Sprintf("unknown (%d)", usage)
+    for candidate, value := range extKeyUsages {
+      if value == usage && candidate != "s/mime" {
+        name = candidate
+      }
+    }
+    names = append(names, name)
+  }
+  return names
+}
+
+// SANs lists the subject alternative names of cert.
+func SANs(cert *x509.Certificate) []string {
+  names := append([]string{}, cert.DNSNames...)
+  for _, ip := range cert.IPAddresses {
+    names = append(names, ip.String())
+  }
+  names = append(names, cert.EmailAddresses...)
+  for _, uri := range cert.URIs {
+    names = append(names, uri.String())
+  }
+  return names
+}
+
+// Role describes the position of cert in a chain.
+func Role(cert *x509.Certificate) string {
+  switch {
+  case !cert.IsCA:
+    return "leaf"
+  case IsSelfSigned(cert):
+    return "root"
+  default:
+    return "intermediate"
+  }
+}
+
+func IsSelfSigned(cert *x509.Certificate) bool {
+  return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// DaysUntilExpiry is negative once cert has expired.
+func DaysUntilExpiry(cert *x509.Certificate, now time.Time) float64 {
+  return cert.NotAfter.Sub(now).Hours() / 24
+}
+
+// CheckOrder reports the first certificate of a bundle that is not signed by
+// the one following it, bundles being ordered leaf first.
+func CheckOrder(certs []*x509.Certificate) error {
+  for i := 0; i+1 < len(certs); i++ {
+    if err := certs[i].CheckSignatureFrom(certs[i+1]); err != nil {
+      return Error(ErrTlsConfig, fmt.Sprintf("certificate %d (%s) is not signed by certificate %d (%s)", i, certs[i].Subject, i+1, certs[i+1].Subject))
+    }
+  }
+  return nil
+}
+
+// VerifyBundle verifies the first certificate of a bundle, using the rest as
+// intermediates. Without roots a self-signed root at the end of the bundle
+// is trusted, otherwise the system roots are used.
+func VerifyBundle(certs []*x509.Certificate, roots *x509.CertPool, now time.Time) error {
+  if len(certs) == 0 {
+    return Error(ErrTlsConfig, "no certificates to verify")
+  }
+
+  if roots == nil && IsSelfSigned(certs[len(certs)-1]) {
+    roots = x509.NewCertPool()
+    roots.AddCert(certs[len(certs)-1])
+  }
+
+  options := x509.VerifyOptions{
+    Roots:         roots,
+    Intermediates: x509.NewCertPool(),
+    CurrentTime:   now,
+    KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+  }
+  for _, cert := range certs[1:] {
+    options.Intermediates.AddCert(cert)
+  }
+
+  if _, err := certs[0].Verify(options); err != nil {
+    return Wrap(ErrTlsConfig, err)
+  }
+  return nil
+}
@@ -0,0 +1,327 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// maxSchemaSize bounds fetched schema documents.
+const maxSchemaSize = 1 << 20
+
+// protoSchemaPrefix marks the dataschema of proto data, which is no JSON Schema.
+const protoSchemaPrefix = "https://type.googleapis.com/"
+
+// Violation is one way event data fails its schema.
+type Violation struct {
+  Location string `json:"location"`
+  Message string `json:"message"`
+}
+
+// String formats the violation with its JSON Pointer, / for the whole data.
+func (violation Violation) String() string {
+  location := violation.Location
+  if location == "" {
+    location = "/"
+  }
+  return fmt.Sprintf("%s: %s", location, violation.Message)
+}
+
+// SchemaError reports every violation of a schema.
+type SchemaError struct {
+  Schema string
+  Violations []Violation
+}
+
+func (err *SchemaError) Error() string {
+  return err.Unwrap().Error()
+}
+
+// Unwrap returns the violations as an ErrInvalidFormat CloudEventError, so
+// errors.As finds the code of schema errors like any other.
+func (err *SchemaError) Unwrap() error {
+  details := make([]string, 0, len(err.Violations))
+  for _, violation := range err.Violations {
+    details = append(details, violation.String())
+  }
+  return Error(ErrInvalidFormat, fmt.Sprintf("data does not match %s: %s", err.Schema, strings.Join(details, "; ")))
+}
+
+// SchemaValidator checks JSON event data against JSON Schemas from a local
+// directory, matched by $id, by the file name in dataschema or by the event
+// type (<type>.json), and optionally fetched from http(s) dataschema URLs.
+type SchemaValidator struct {
+  fetch bool
+  files map[string]string
+
+  mu sync.Mutex
+  compiler *jsonschema.Compiler
+  schemas map[string]*jsonschema.Schema
+}
+
+// NewSchemaValidator indexes the *.json schemas in dir, which may be empty.
+// Remote dataschemas are only fetched when fetch is set, since listeners
+// would otherwise make requests chosen by senders.
+func NewSchemaValidator(dir string, fetch bool) (*SchemaValidator, error) {
+  validator := &SchemaValidator{
+    fetch:    fetch,
+    files:    map[string]string{},
+    compiler: jsonschema.NewCompiler(),
+    schemas:  map[string]*jsonschema.Schema{},
+  }
+
+  loaders := jsonschema.SchemeURLLoader{}
+  if fetch {
+    loaders["http"] = httpSchemaLoader{client: &http.Client{Timeout: 10 * time.Second}}
+    loaders["https"] = loaders["http"]
+  }
+  validator.compiler.UseLoader(loaders)
+
+  if dir == "" {
+    return validator, nil
+  }
+
+  // Schemas may only $ref local files inside dir, fetched ones included
+  root, err := filepath.Abs(dir)
+  if err == nil {
+    root, err = filepath.EvalSymlinks(root)
+  }
+  if err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+  loaders["file"] = fileSchemaLoader{root: root}
+
+  err = filepath.WalkDir(dir, func(file string, entry fs.DirEntry, err error) error {
+    if err != nil || entry.IsDir() || filepath.Ext(file) != ".json" {
+      return err
+    }
+    return validator.add(file)
+  })
+  if err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+  return validator, nil
+}
+
+// add registers a schema under its $id, or its file URL, and indexes it by
+// both and by file name.
+func (validator *SchemaValidator) add(file string) error {
+  content, err := os.Open(file)
+  if err != nil {
+    return err
+  }
+  defer func() { _ = content.Close() }()
+
+  doc, err := jsonschema.UnmarshalJSON(content)
+  if err != nil {
+    return fmt.Errorf("%s: %v", file, err)
+  }
+
+  absolute, err := filepath.Abs(file)
+  if err != nil {
+    return err
+  }
+  location := (&url.URL{Scheme: "file", Path: filepath.ToSlash(absolute)}).String()
+  if object, ok := doc.(map[string]any); ok {
+    if id, ok := object["$id"].(string); ok && id != "" {
+      location = id
+    }
+  }
+
+  if err := validator.compiler.AddResource(location, doc); err != nil {
+    return fmt.Errorf("%s: %v", file, err)
+  }
+  validator.files[location] = location
+  validator.files[filepath.Base(file)] = location
+  return nil
+}
+
+// resolve finds the schema for an event, returning an empty location when
+// none applies.
+func (validator *SchemaValidator) resolve(event cloudevents.Event) string {
+  schema := event.DataSchema()
+  if strings.HasPrefix(schema, protoSchemaPrefix) {
+    return ""
+  }
+
+  if schema != "" {
+    if location, ok := validator.files[schema]; ok {
+      return location
+    }
+    if target, err := url.Parse(schema); err == nil {
+      name := path.Base(target.Path)
+      for _, candidate := range []string{name, name + ".json"} {
+        if location, ok := validator.files[candidate]; ok {
+          return location
+        }
+      }
+      if validator.fetch && (target.Scheme == "http" || target.Scheme == "https") {
+        return schema
+      }
+    }
+  }
+
+  if location, ok := validator.files[event.Type()+".json"]; ok {
+    return location
+  }
+  return ""
+}
+
+// Validate checks the event data and returns the location of the schema
+// used, empty when none applies, with every violation found.
+func (validator *SchemaValidator) Validate(event cloudevents.Event) (string, []Violation, error) {
+  location := validator.resolve(event)
+  if location == "" {
+    return "", nil, nil
+  }
+
+  validator.mu.Lock()
+  schema, ok := validator.schemas[location]
+  if !ok {
+    var err error
+    if schema, err = validator.compiler.Compile(location); err != nil {
+      validator.mu.Unlock()
+      return location, nil, Error(ErrInvalidFormat, fmt.Sprintf("schema %s: %v", location, err))
+    }
+    validator.schemas[location] = schema
+  }
+  validator.mu.Unlock()
+
+  if contentType := event.DataContentType(); contentType != "" && !isJSON(contentType) {
+    return location, []Violation{{Location: "", Message: fmt.Sprintf("data is %s, not JSON", contentType)}}, nil
+  }
+
+  data, err := jsonschema.UnmarshalJSON(bytes.NewReader(event.Data()))
+  if err != nil {
+    return location, []Violation{{Location: "", Message: fmt.Sprintf("data is not valid JSON: %v", err)}}, nil
+  }
+
+  err = schema.Validate(data)
+  if failure, ok := err.(*jsonschema.ValidationError); ok {
+    return location, violations(failure, message.NewPrinter(language.English)), nil
+  }
+  if err != nil {
+    return location, nil, Wrap(ErrInvalidFormat, err)
+  }
+  return location, nil, nil
+}
+
+// Check returns a SchemaError when the event data violates its schema.
+func (validator *SchemaValidator) Check(event cloudevents.Event) error {
+  location, found, err := validator.Validate(event)
+  if err != nil {
+    return err
+  }
+  if len(found) > 0 {
+    return &SchemaError{Schema: location, Violations: found}
+  }
+  return nil
+}
+
+// violations flattens the leaves of a validation error tree.
+func violations(failure *jsonschema.ValidationError, printer *message.Printer) []Violation {
+  if len(failure.Causes) == 0 {
+    location := ""
+    for _, token := range failure.InstanceLocation {
+      location += "/" + strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+    }
+    return []Violation{{Location: location, Message: failure.ErrorKind.LocalizedString(printer)}}
+  }
+
+  var found []Violation
+  for _, cause := range failure.Causes {
+    found = append(found, violations(cause, printer)...)
+  }
+  return found
+}
+
+// fileSchemaLoader loads file URLs that resolve inside root.
+type fileSchemaLoader struct {
+  root string
+}
+
+func (loader fileSchemaLoader) Load(location string) (any, error) {
+  file, err := jsonschema.FileLoader{}.ToFile(location)
+  if err != nil {
+    return nil, err
+  }
+  if file, err = filepath.EvalSymlinks(file); err != nil {
+    return nil, err
+  }
+  relative, err := filepath.Rel(loader.root, file)
+  if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+    return nil, fmt.Errorf("%s is outside the schema directory %s", location, loader.root)
+  }
+
+  content, err := os.Open(file)
+  if err != nil {
+    return nil, err
+  }
+  defer func() { _ = content.Close() }()
+  return jsonschema.UnmarshalJSON(content)
+}
+
+type httpSchemaLoader struct {
+  client *http.Client
+}
+
+func (loader httpSchemaLoader) Load(location string) (any, error) {
+  response, err := loader.client.Get(location)
+  if err != nil {
+    return nil, err
+  }
+  defer func() { _ = response.Body.Close() }()
+
+  if response.StatusCode != http.StatusOK {
+    return nil, fmt.Errorf("%s returned %s", location, response.Status)
+  }
+  log.Printf("Fetched JSON Schema %s", location)
+  return jsonschema.UnmarshalJSON(io.LimitReader(response.Body, maxSchemaSize))
+}
+
+// MARK: - Problem Details
+
+// Problem is an RFC 9457 problem details body.
+type Problem struct {
+  Type string `json:"type"`
+  Title string `json:"title"`
+  Status int `json:"status"`
+  Detail string `json:"detail,omitempty"`
+  Schema string `json:"schema,omitempty"`
+  Violations []Violation `json:"violations,omitempty"`
+}
+
+// writeProblem rejects a request with a problem details body describing err.
+func writeProblem(w http.ResponseWriter, status int, err error) {
+  problem := Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: err.Error()}
+  var failure *SchemaError
+  if errors.As(err, &failure) {
+    problem.Detail = "event data does not match its schema"
+    problem.Schema = failure.Schema
+    problem.Violations = failure.Violations
+  }
+
+  w.Header().Set("Content-Type", "application/problem+json")
+  w.WriteHeader(status)
+  _ = json.NewEncoder(w).Encode(problem)
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+func writeSchema(t *testing.T, file string, schema string) {
+  t.Helper()
+  if err := os.WriteFile(file, []byte(schema), 0o600); err != nil {
+    t.Fatal(err)
+  }
+}
+
+func TestFetchedSchemasCannotReferenceFilesOutsideTheSchemaDirectory(t *testing.T) {
+  outside := filepath.Join(t.TempDir(), "secret.json")
+  writeSchema(t, outside, `{"type": "string"}`)
+
+  dir := t.TempDir()
+  writeSchema(t, filepath.Join(dir, "item.json"), `{"type": "object", "required": ["id"]}`)
+  writeSchema(t, filepath.Join(dir, "com.example.order.json"), `{"$ref": "item.json"}`)
+
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+    fmt.Fprintf(w, `{"$ref": %q}`, "file://"+filepath.ToSlash(outside))
+  }))
+  defer server.Close()
+
+  validator, err := NewSchemaValidator(dir, true)
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  local := cloudevents.NewEvent()
+  local.SetType("com.example.order")
+  if err := local.SetData(cloudevents.ApplicationJSON, map[string]any{"id": "42"}); err != nil {
+    t.Fatal(err)
+  }
+  if err := validator.Check(local); err != nil {
+    t.Errorf("local $ref inside the schema directory: %v", err)
+  }
+
+  remote := local.Clone()
+  remote.SetDataSchema(server.URL + "/order.schema")
+  err = validator.Check(remote)
+  if err == nil || !strings.Contains(err.Error(), "outside the schema directory") {
+    t.Errorf("Check = %v, want the file outside the schema directory refused", err)
+  }
+}
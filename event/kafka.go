@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/cloudevents/sdk-go/protocol/kafka_sarama/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+)
+
+// KafkaConfig selects the brokers, topic and consumer group used by the Kafka
+// binding. Events carrying the partitionkey extension use it as message key.
+type KafkaConfig struct {
+  Brokers []string `envconfig:"CE_KAFKA_BROKERS"`
+  Topic string `envconfig:"CE_KAFKA_TOPIC" default:"cloudevents"`
+  Group string `envconfig:"CE_KAFKA_GROUP" default:"cecli"`
+}
+
+func (config CloudEventConfig) kafkaClient(ctx context.Context) (cloudevents.Client, error) {
+  mode, err := ParseMode(config.Mode)
+  if err != nil {
+    return nil, err
+  }
+
+  settings, err := config.sarama(ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  sender, err := kafka_sarama.NewSender(config.kafkaBrokers(), settings, config.Kafka.Topic)
+  if err != nil {
+    return nil, Error(ErrSendFailed, fmt.Sprintf("Failed to create Kafka sender: %v", err))
+  }
+  return newClient(sender, mode, cloudevents.WithTimeNow(), cloudevents.WithUUIDs())
+}
+
+func (config CloudEventConfig) kafkaReceiver(ctx context.Context) (cloudevents.Client, error) {
+  settings, err := config.sarama(ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  consumer, err := kafka_sarama.NewConsumer(config.kafkaBrokers(), settings, config.Kafka.Group, config.Kafka.Topic)
+  if err != nil {
+    return nil, Error(ErrReceiveFailed, fmt.Sprintf("Failed to create Kafka consumer: %v", err))
+  }
+  return newClient(consumer, binding.EncodingUnknown)
+}
+
+// sarama builds the client settings, enabling TLS unless running insecure.
+func (config CloudEventConfig) sarama(ctx context.Context) (*sarama.Config, error) {
+  settings := sarama.NewConfig()
+  settings.Version = sarama.V2_0_0_0
+  settings.Producer.Return.Successes = true
+
+  if !config.Insecure {
+    var hosts []string
+    for _, broker := range config.kafkaBrokers() {
+      hosts = append(hosts, hostOf(broker))
+    }
+    if err := config.loadTLS(ctx, hosts...); err != nil {
+      return nil, err
+    }
+    settings.Net.TLS.Enable = true
+    settings.Net.TLS.Config = config.Config
+  }
+  return settings, nil
+}
+
+// kafkaBrokers falls back to the configured address and port when no brokers
+// are listed.
+func (config CloudEventConfig) kafkaBrokers() []string {
+  if len(config.Kafka.Brokers) > 0 {
+    return config.Kafka.Brokers
+  }
+  return []string{fmt.Sprintf("%s:%d", config.Address, config.Port)}
+}
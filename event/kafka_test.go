@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/cloudevents/sdk-go/protocol/kafka_sarama/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+
+	api "github.com/anselmes/ce-go-template/api/v1"
+)
+
+const (
+  testTopic = "orders"
+  testGroup = "cecli-test"
+)
+
+// sendKafka sends an event with a partitionkey through a mock producer and
+// returns the message it produced.
+func sendKafka(t *testing.T) *sarama.ProducerMessage {
+  t.Helper()
+
+  var produced *sarama.ProducerMessage
+  producer := mocks.NewSyncProducer(t, nil)
+  producer.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(message *sarama.ProducerMessage) error {
+    produced = message
+    return nil
+  })
+
+  sender, err := kafka_sarama.NewSenderFromSyncProducer(testTopic, producer)
+  if err != nil {
+    t.Fatal(err)
+  }
+  client, err := newClient(sender, binding.EncodingBinary, cloudevents.WithTimeNow())
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  manager := NewCloudEventManager(&api.Data{Message: "created"}, &CloudEventOptions{Source: "/orders", Type: "com.example.order", ID: "order-42"})
+  manager.Event.SetExtension("partitionkey", "customer-7")
+  manager.SetRetry(1)
+
+  if _, err := manager.Send(context.Background(), client); err != nil {
+    t.Fatalf("Send: %v", err)
+  }
+  if err := producer.Close(); err != nil {
+    t.Fatal(err)
+  }
+  if produced == nil {
+    t.Fatal("no message was produced")
+  }
+  return produced
+}
+
+func TestKafkaSendUsesPartitionKey(t *testing.T) {
+  produced := sendKafka(t)
+
+  if produced.Topic != testTopic {
+    t.Errorf("topic = %q, want %q", produced.Topic, testTopic)
+  }
+  key, err := produced.Key.Encode()
+  if err != nil {
+    t.Fatal(err)
+  }
+  if string(key) != "customer-7" {
+    t.Errorf("message key = %q, want customer-7", key)
+  }
+
+  headers := map[string]string{}
+  for _, header := range produced.Headers {
+    headers[string(header.Key)] = string(header.Value)
+  }
+  if headers["ce_id"] != "order-42" || headers["ce_type"] != "com.example.order" {
+    t.Errorf("binary mode headers missing from %v", headers)
+  }
+}
+
+func TestKafkaReceiveThroughConsumerGroup(t *testing.T) {
+  produced := sendKafka(t)
+
+  broker := sarama.NewMockBroker(t, 1)
+  defer broker.Close()
+
+  // Serve the produced message, headers included, at offset 0
+  fetch := &sarama.FetchResponse{Version: 7}
+  fetch.AddRecord(testTopic, 0, produced.Key, produced.Value, 0)
+  record := fetch.GetBlock(testTopic, 0).RecordsSet[0].RecordBatch.Records[0]
+  for _, header := range produced.Headers {
+    record.Headers = append(record.Headers, &sarama.RecordHeader{Key: header.Key, Value: header.Value})
+  }
+  fetch.GetBlock(testTopic, 0).HighWaterMarkOffset = 1
+
+  broker.SetHandlerByMap(map[string]sarama.MockResponse{
+    "ApiVersionsRequest": sarama.NewMockApiVersionsResponse(t),
+    "MetadataRequest": sarama.NewMockMetadataResponse(t).
+      SetBroker(broker.Addr(), broker.BrokerID()).
+      SetLeader(testTopic, 0, broker.BrokerID()),
+    "FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+      SetCoordinator(sarama.CoordinatorGroup, testGroup, broker),
+    "JoinGroupRequest": sarama.NewMockJoinGroupResponse(t).SetGroupProtocol(sarama.RangeBalanceStrategyName),
+    "SyncGroupRequest": sarama.NewMockSyncGroupResponse(t).SetMemberAssignment(&sarama.ConsumerGroupMemberAssignment{
+      Topics: map[string][]int32{testTopic: {0}},
+    }),
+    "HeartbeatRequest": sarama.NewMockHeartbeatResponse(t),
+    "OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).SetOffset(testGroup, testTopic, 0, 0, "", sarama.ErrNoError),
+    "OffsetRequest": sarama.NewMockOffsetResponse(t).
+      SetOffset(testTopic, 0, sarama.OffsetOldest, 0).
+      SetOffset(testTopic, 0, sarama.OffsetNewest, 1),
+    "FetchRequest": sarama.NewMockWrapper(fetch),
+    "OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+    "LeaveGroupRequest": sarama.NewMockLeaveGroupResponse(t),
+  })
+
+  config := &CloudEventConfig{
+    Protocol: ProtocolKafka,
+    Insecure: true,
+    Kafka: KafkaConfig{Brokers: []string{broker.Addr()}, Topic: testTopic, Group: testGroup},
+  }
+
+  ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+  defer cancel()
+
+  received := make(chan cloudevents.Event, 1)
+  manager := NewCloudEventManager(&api.Data{}, nil)
+  done := make(chan error, 1)
+  go func() {
+    done <- manager.Listen(ctx, config, func(ctx context.Context, event cloudevents.Event) error {
+      select {
+      case received <- event:
+      default:
+      }
+      return nil
+    })
+  }()
+
+  select {
+  case event := <-received:
+    if event.ID() != "order-42" || event.Type() != "com.example.order" {
+      t.Errorf("received %s %s, want order-42 com.example.order", event.ID(), event.Type())
+    }
+    value, _ := produced.Value.Encode()
+    if string(event.Data()) != string(value) {
+      t.Errorf("data = %s, want %s", event.Data(), value)
+    }
+  case err := <-done:
+    t.Fatalf("Listen stopped before receiving: %v", err)
+  case <-ctx.Done():
+    t.Fatal("no event received from the consumer group")
+  }
+
+  cancel()
+  <-done
+}
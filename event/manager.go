@@ -5,111 +5,216 @@ package cloudevent
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	api "github.com/anselmes/ce-go-template/api/v1"
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/protocol"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
-type callback func(event cloudevents.Event)
+type callback func(ctx context.Context, event cloudevents.Event) error
+
+// sender performs one delivery attempt for the retry loop.
+type sender func(ctx context.Context) protocol.Result
 
 type CloudEventManager struct {
   Data *api.Data
   Event cloudevents.Event
-  retry int
-  timeout int
+  retry Retry
   uri string
   cetype string
   callback callback
+  format format.Format
+  hub *websocketHub
+  options *CloudEventOptions
+  payload []byte
+  contentType string
+  message proto.Message
+  unknownType string
+  schemas *SchemaValidator
 }
 
-func (manager *CloudEventManager) RetryCount() int { return manager.retry }
-func (manager *CloudEventManager) Timeout() time.Duration { return time.Duration(manager.timeout) }
+func (manager *CloudEventManager) RetryCount() int { return manager.retry.Attempts }
+func (manager *CloudEventManager) Timeout() time.Duration { return time.Duration(manager.retry.Timeout) * time.Millisecond }
+func (manager *CloudEventManager) RetryPolicy() Retry { return manager.retry }
+func (manager *CloudEventManager) Format() format.Format { return manager.format }
 
-func (manager *CloudEventManager) SetRetry(count int) { manager.retry = count }
-func (manager *CloudEventManager) SetTimeout(time time.Duration) { manager.timeout = int(time) }
+func (manager *CloudEventManager) SetRetry(count int) { manager.retry.Attempts = count }
+func (manager *CloudEventManager) SetTimeout(timeout time.Duration) { manager.retry.Timeout = int(timeout.Milliseconds()) }
+func (manager *CloudEventManager) SetAttemptTimeout(timeout time.Duration) { manager.retry.AttemptTimeout = timeout }
+func (manager *CloudEventManager) SetRetryPolicy(retry Retry) { manager.retry = retry }
 func (manager *CloudEventManager) SetCallback(cb callback) { manager.callback = cb }
+func (manager *CloudEventManager) SetSchemas(schemas *SchemaValidator) { manager.schemas = schemas }
+
+// SetFormat selects the structured event format. Protobuf events carry the
+// data as a typed api.Data message instead of JSON text.
+func (manager *CloudEventManager) SetFormat(f format.Format) error {
+  manager.format = f
+  if IsProtobuf(f) {
+    return manager.setData()
+  }
+  return nil
+}
+
+func (manager *CloudEventManager) Send(ctx context.Context, client cloudevents.Client) (*DeliveryReport, error) {
+  if manager.format != nil {
+    ctx = binding.UseFormatForEvent(ctx, manager.format)
+  }
+  return manager.deliver(ctx, func(ctx context.Context) protocol.Result {
+    return client.Send(ctx, manager.Event)
+  })
+}
 
-func (manager *CloudEventManager) Send(ctx context.Context, client cloudevents.Client) {
-  count := manager.retry
-  timeout := manager.timeout
+// deliver runs send under the retry policy until it is acknowledged, rejected
+// permanently, or the attempts, budget or context run out.
+func (manager *CloudEventManager) deliver(ctx context.Context, send sender) (*DeliveryReport, error) {
+  policy := manager.retry
+  count := policy.Attempts
+  report := &DeliveryReport{}
+  begin := time.Now()
 
+  if count < 1 {
+    count = 1
+  }
+
+  var delay time.Duration
   for i := 0; i < count; i++ {
-    result := client.Send(ctx, manager.Event)
+    if ctx.Err() != nil {
+      break
+    }
+
+    hint, result := manager.attempt(ctx, send, report)
 
     if cloudevents.IsACK(result) {
-      log.Printf("Result: 200")
-      break // Success - exit retry loop
-    } else if cloudevents.IsNACK(result) {
-      log.Printf("CloudEvent was rejected: %v", result)
-      if i == count-1 {
-        log.Fatalln(Error(ErrNotAccepted, result.Error()))
+      if report.StatusCode > 0 {
+        log.Printf("Result: %d", report.StatusCode)
+      } else {
+        log.Printf("Result: %s", report.Status)
       }
-    } else if cloudevents.IsUndelivered(result) {
-      log.Printf("CloudEvent delivery failed: %v", result)
-      if i == count-1 {
-        log.Fatalln(Error(ErrSendFailed, result.Error()))
+      return report, nil
+    } else if report.Status == StatusNotAccepted {
+      log.Printf("CloudEvent was rejected: %v", result)
+      if !policy.retryable(report.StatusCode) {
+        log.Printf("Status %d is not retryable, giving up", report.StatusCode)
+        break
       }
     } else {
-      log.Printf("Result: %v", result)
-      if i == count-1 {
-        log.Printf("Exhausted all retry attempts")
-      }
+      log.Printf("CloudEvent delivery failed: %v", result)
     }
 
     // Only sleep and retry if this isn't the last attempt
     if i < count-1 {
-      time.Sleep(time.Duration(timeout) * time.Millisecond)
-      log.Printf("Retrying to send CloudEvent, attempt %d/%d", i+1, count)
+      delay = policy.delay(i+1, delay)
+      if hint.delay > delay {
+        delay = hint.delay
+      }
+
+      if policy.MaxElapsed > 0 && time.Since(begin)+delay > policy.MaxElapsed {
+        log.Printf("Retry budget of %s exhausted", policy.MaxElapsed)
+        break
+      }
+
+      log.Printf("Retrying to send CloudEvent in %s, attempt %d/%d", delay, i+2, count)
+      if err := sleep(ctx, delay); err != nil {
+        break
+      }
     }
   }
+
+  if err := ctx.Err(); err != nil {
+    report.Status = StatusUndelivered
+    return report, Wrap(ErrSendFailed, err, fmt.Sprintf("send interrupted after %d attempt(s): %v", len(report.Attempts), err))
+  }
+
+  last := report.Attempts[len(report.Attempts)-1].Err
+  if report.Status == StatusNotAccepted {
+    return report, Wrap(ErrNotAccepted, last)
+  }
+  return report, Wrap(ErrSendFailed, last)
+}
+
+// attempt performs a single send bounded by the per-attempt timeout and
+// records it in the report.
+func (manager *CloudEventManager) attempt(ctx context.Context, send sender, report *DeliveryReport) (*retryAfter, protocol.Result) {
+  ctx, hint := withRetryAfter(ctx)
+  if manager.retry.AttemptTimeout > 0 {
+    var cancel context.CancelFunc
+    ctx, cancel = context.WithTimeout(ctx, manager.retry.AttemptTimeout)
+    defer cancel()
+  }
+
+  start := time.Now()
+  result := send(ctx)
+  report.record(len(report.Attempts)+1, result, time.Since(start))
+
+  return hint, result
 }
 
 func (manager *CloudEventManager) Listen(ctx context.Context, config *CloudEventConfig, callback callback) error {
-	manager.SetCallback(callback)
+  if !config.IsHTTP() {
+    receiver, err := config.Receiver(ctx)
+    if err != nil {
+      return err
+    }
+    defer func() { _ = Close(context.Background(), receiver) }()
 
-	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%d", config.Address, config.Port),
-		Handler: manager.Handler(),
-	}
+    log.Printf("Listening for CloudEvent on %s...", config.Url())
+    return manager.Receive(ctx, receiver, callback)
+  }
 
+  manager.SetCallback(callback)
 
-  log.Printf("Listening for CloudEvent on %s...", config.Url())
+  server := &http.Server{
+    Addr:    fmt.Sprintf("%s:%d", config.Address, config.Port),
+    Handler: manager.Handler(),
+  }
 
-	var err error
-	if config.Insecure {
-		err = server.ListenAndServe()
-	} else {
-		// Load TLS configuration
-		cert, loadErr := tls.LoadX509KeyPair(config.Certificate, config.CertificateKey)
-		if loadErr != nil {
-			return Error(ErrTlsConfig, fmt.Sprintf("Failed to load TLS certificates: %v", loadErr))
-		}
-		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
-		err = server.ListenAndServeTLS("", "")
-	}
+  if config.protocol() == ProtocolWebSocket {
+    server.Handler = manager.WebSocketHandler(config, server.Handler)
+    server.RegisterOnShutdown(manager.hub.close)
+  }
 
-	if err != nil && err != http.ErrServerClosed {
-		return Error(ErrReceiveFailed, fmt.Sprintf("Server failed: %v", err))
-	}
+  if !config.Insecure && !config.IsSocket() {
+    // Load TLS configuration
+    tlsConfig, err := config.ServerTLS(ctx)
+    if err != nil {
+      return err
+    }
+    server.TLSConfig = tlsConfig
+    server.Handler = withMetrics(identify(server.Handler), tlsConfig)
+  }
 
-	return nil
+  listener, err := config.Listener()
+  if err != nil {
+    return err
+  }
+
+  log.Printf("Listening for CloudEvent on %s...", config.Endpoint())
+
+  return Serve(ctx, server, listener, config.ShutdownTimeout)
 }
 
 func (manager *CloudEventManager) Receive(ctx context.Context, client cloudevents.Client, callback callback) error {
-	if err := client.StartReceiver(ctx, callback); err != nil {
-		return Error(ErrReceiveFailed, err.Error())
+  manager.SetCallback(callback)
+
+	if err := client.StartReceiver(ctx, manager.dispatch); err != nil {
+		return Wrap(ErrReceiveFailed, err)
 	}
 	return nil
 }
 
-func (manager *CloudEventManager) Display(event cloudevents.Event) {
+func (manager *CloudEventManager) Display(ctx context.Context, event cloudevents.Event) error {
   log.Printf("Context Attributes,")
   log.Printf("  specversion: %s", event.SpecVersion())
   log.Printf("  type: %s", event.Type())
@@ -117,13 +222,44 @@ func (manager *CloudEventManager) Display(event cloudevents.Event) {
   log.Printf("  id: %s", event.ID())
   log.Printf("  datacontenttype: %s", event.DataContentType())
 
+  if peer, ok := PeerFromContext(ctx); ok {
+    log.Printf("Peer,")
+    log.Printf("  subject: %s", peer.Subject)
+    log.Printf("  sans: %s", strings.Join(peer.SANs(), ", "))
+  }
+
   log.Printf("Data,")
+  if message, ok := MessageFromContext(ctx); ok {
+    log.Printf("  %s: %s", message.ProtoReflect().Descriptor().FullName(), protojson.MarshalOptions{}.Format(message))
+    return nil
+  }
+  if event.DataContentType() == protobuf.ContentTypeProtobuf && event.DataSchema() == typeUrl(&api.Data{}) {
+    data := &api.Data{}
+    if err := event.DataAs(data); err == nil {
+      log.Printf("  %s", protojson.MarshalOptions{}.Format(data))
+      return nil
+    }
+  }
   log.Printf("  %s", string(event.Data()))
+
+  return nil
 }
 
 func (manager *CloudEventManager) Handler() http.Handler {
   return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-    log.Println("Received HTTP request for CloudEvent")
+    mode := RequestMode(req)
+    log.Printf("Received %s mode HTTP request for CloudEvent", mode)
+
+    // A listener bound to a format refuses structured events in any other
+    if mode == binding.EncodingStructured && manager.format != nil && !strings.HasPrefix(req.Header.Get("Content-Type"), manager.format.MediaType()) {
+      http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+      return
+    }
+
+    if mode == binding.EncodingBatch {
+      manager.handleBatch(w, req)
+      return
+    }
 
     event, err := cloudevents.NewEventFromHTTPRequest(req)
     if err != nil {
@@ -131,54 +267,195 @@ func (manager *CloudEventManager) Handler() http.Handler {
       return
     }
 
-    // Use the callback if set, otherwise use Display as default
-    if manager.callback != nil {
-      manager.callback(*event)
-    } else {
-      manager.Display(*event)
+    ctx, err := manager.accept(req.Context(), *event)
+    if err != nil {
+      writeProblem(w, http.StatusBadRequest, err)
+      return
+    }
+
+    if err := manager.handle(ctx, *event); err != nil {
+      http.Error(w, err.Error(), http.StatusInternalServerError)
+      return
     }
 
     w.WriteHeader(http.StatusOK)
   })
 }
 
+// dispatch validates the event data, then hands the event on.
+func (manager *CloudEventManager) dispatch(ctx context.Context, event cloudevents.Event) error {
+  ctx, err := manager.accept(ctx, event)
+  if err != nil {
+    return err
+  }
+  return manager.handle(ctx, event)
+}
+
+// accept checks the data against its JSON Schema, when validation is
+// enabled, and decodes the data of registered types.
+func (manager *CloudEventManager) accept(ctx context.Context, event cloudevents.Event) (context.Context, error) {
+  if manager.schemas != nil {
+    if err := manager.schemas.Check(event); err != nil {
+      return ctx, err
+    }
+  }
+  return manager.typed(ctx, event)
+}
+
+// handle hands an event to the callback if set, otherwise to Display, and
+// streams handled events to any connected WebSocket clients.
+func (manager *CloudEventManager) handle(ctx context.Context, event cloudevents.Event) error {
+  handle := manager.Display
+  if manager.callback != nil {
+    handle = manager.callback
+  }
+
+  if err := handle(ctx, event); err != nil {
+    return err
+  }
+  if manager.hub != nil {
+    manager.hub.broadcast(event)
+  }
+  return nil
+}
+
 func (manager *CloudEventManager) Json() ([]byte, error) {
   result, err := json.Marshal(manager.Event)
   if err != nil {
-    return nil, Error(ErrInvalidFormat, err.Error())
+    return nil, Wrap(ErrInvalidFormat, err)
   }
   return result, nil
 }
 
-func (manager *CloudEventManager) FromJson(bytes []byte) {
-  err := json.Unmarshal(bytes, manager.Data)
+// Encode serializes the event using the selected format, JSON by default.
+func (manager *CloudEventManager) Encode() ([]byte, error) {
+  f := manager.format
+  if f == nil {
+    f = format.JSON
+  }
+
+  result, err := f.Marshal(&manager.Event)
+  if err != nil {
+    return nil, Wrap(ErrInvalidFormat, err)
+  }
+  return result, nil
+}
+
+// Validate checks the event against the spec version it uses and its data
+// against its JSON Schema, when validation is enabled.
+func (manager *CloudEventManager) Validate() error {
+  if err := manager.Event.Validate(); err != nil {
+    return Wrap(ErrInvalidFormat, err)
+  }
+  if manager.schemas != nil {
+    return manager.schemas.Check(manager.Event)
+  }
+  return nil
+}
+
+// setData attaches the payload to the event. Decoded messages, or Data when
+// there is no payload, are sent as proto_data when using protobuf, as are
+// JSON payloads that strictly decode into Data.
+func (manager *CloudEventManager) setData() error {
+  message := manager.message
+  if message == nil && manager.payload == nil {
+    message = manager.Data
+  }
+  if message == nil && IsProtobuf(manager.format) && isJSON(manager.contentType) {
+    data := &api.Data{}
+    if protojson.Unmarshal(manager.payload, data) == nil {
+      message = data
+    }
+  }
+
+  var err error
+  switch {
+  case message == nil:
+    manager.setPayload()
+  case IsProtobuf(manager.format):
+    manager.Event.SetDataSchema(typeUrl(message))
+    err = manager.Event.SetData(protobuf.ContentTypeProtobuf, message)
+  case message == proto.Message(manager.Data):
+    err = manager.Event.SetData(cloudevents.ApplicationJSON, manager.Data)
+  default:
+    var encoded []byte
+    if encoded, err = protojson.Marshal(message); err != nil {
+      break
+    }
+    manager.Event.SetDataSchema(typeUrl(message))
+    manager.Event.SetDataContentType(cloudevents.ApplicationJSON)
+    manager.Event.DataEncoded = encoded
+    manager.Event.DataBase64 = false
+  }
   if err != nil {
-    log.Fatalln(Error(ErrInvalidFormat, err.Error()))
+    return Wrap(ErrInvalidFormat, err)
+  }
+  manager.overrideData()
+  return nil
+}
+
+// setPayload attaches the raw payload as-is. SetData would run it through the
+// 0.3 data codecs, which only know JSON and XML and lose anything else.
+func (manager *CloudEventManager) setPayload() {
+  manager.Event.SetDataContentType(manager.contentType)
+  manager.Event.DataEncoded = manager.payload
+  manager.Event.DataBase64 = false
+  if isText(manager.contentType) {
     return
   }
-  manager.Event.SetData(cloudevents.ApplicationJSON, manager.Data)
+  if manager.Event.SpecVersion() == cloudevents.VersionV1 {
+    manager.Event.DataBase64 = true
+  } else {
+    manager.Event.SetDataContentEncoding(cloudevents.Base64)
+  }
+}
+
+// overrideData applies the content type and schema given in the options over
+// the ones derived from the data.
+func (manager *CloudEventManager) overrideData() {
+  if manager.options.DataContentType != "" {
+    manager.Event.SetDataContentType(manager.options.DataContentType)
+  }
+  if manager.options.DataSchema != "" {
+    manager.Event.SetDataSchema(manager.options.DataSchema)
+  }
 }
 
 func NewCloudEventManager(data *api.Data, opts *CloudEventOptions) *CloudEventManager {
   manager := &CloudEventManager{Data: data}
+  manager.retry = Retry{Attempts: 1, RetryableStatus: DefaultRetryableStatus}
 
   event := cloudevents.NewEvent()
   event.SetID(uuid.New().String())
   source := "ce/uri"
   cetype := "ce.type"
 
-  if opts != nil {
-    if opts.Source != "" { source = opts.Source }
-    if opts.Type != "" { cetype = opts.Type }
+  if opts == nil {
+    opts = &CloudEventOptions{}
+  }
+  if opts.Source != "" { source = opts.Source }
+  if opts.Type != "" { cetype = opts.Type }
+  manager.options = opts
+
+  // The spec version goes first since changing it converts the context
+  if manager.options.SpecVersion != "" { event.SetSpecVersion(manager.options.SpecVersion) }
+  if manager.options.ID != "" { event.SetID(manager.options.ID) }
+  if manager.options.Subject != "" { event.SetSubject(manager.options.Subject) }
+  if !manager.options.Time.IsZero() { event.SetTime(manager.options.Time) }
+  for name, value := range manager.options.Extensions {
+    event.SetExtension(name, value)
   }
 
   event.SetSource(source)
   event.SetType(cetype)
-  event.SetData(cloudevents.ApplicationJSON, data.Message)
+  if err := event.SetData(cloudevents.ApplicationJSON, data.Message); err != nil {
+    log.Printf("Failed to set data: %v", err)
+  }
 
   manager.uri = source
   manager.cetype = cetype
   manager.Event = event
+  manager.overrideData()
 
   return manager
 }
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// MetricsPath serves listener metrics in the Prometheus text format.
+const MetricsPath = "/metrics"
+
+// withMetrics answers GET requests on MetricsPath with the days until the
+// serving certificate expires and passes every other request to next. The
+// certificate is read on each scrape so reloads are reflected.
+func withMetrics(next http.Handler, config *tls.Config) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+    if req.Method != http.MethodGet || req.URL.Path != MetricsPath {
+      next.ServeHTTP(w, req)
+      return
+    }
+
+    w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+    fmt.Fprintln(w, "# HELP cecli_tls_certificate_expiry_days Days until the serving certificate expires.")
+    fmt.Fprintln(w, "# TYPE cecli_tls_certificate_expiry_days gauge")
+
+    cert, err := config.GetCertificate(&tls.ClientHelloInfo{})
+    if err != nil || len(cert.Certificate) == 0 {
+      return
+    }
+    leaf, err := x509.ParseCertificate(cert.Certificate[0])
+    if err != nil {
+      return
+    }
+    fmt.Fprintf(w, "cecli_tls_certificate_expiry_days{subject=%q,serial=\"%X\"} %.2f\n", leaf.Subject.String(), leaf.SerialNumber, DaysUntilExpiry(leaf, time.Now()))
+  })
+}
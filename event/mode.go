@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudevents/sdk-go/v2/binding"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+// ParseMode maps a content mode name to its CloudEvents encoding. An empty mode
+// leaves the choice to the protocol binding.
+func ParseMode(mode string) (binding.Encoding, error) {
+  switch strings.ToLower(mode) {
+  case "":
+    return binding.EncodingUnknown, nil
+  case "binary":
+    return binding.EncodingBinary, nil
+  case "structured":
+    return binding.EncodingStructured, nil
+  case "batch":
+    return binding.EncodingBatch, nil
+  default:
+    return binding.EncodingUnknown, Error(ErrInvalidFormat, fmt.Sprintf("unknown content mode %q", mode))
+  }
+}
+
+// RequestMode reports which content mode an incoming HTTP request used.
+func RequestMode(req *http.Request) binding.Encoding {
+  contentType := req.Header.Get(cehttp.ContentType)
+
+  switch {
+  case cehttp.IsHTTPBatch(req.Header):
+    return binding.EncodingBatch
+  case strings.HasPrefix(contentType, "application/cloudevents"):
+    return binding.EncodingStructured
+  default:
+    return binding.EncodingBinary
+  }
+}
@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+
+	"github.com/cloudevents/sdk-go/protocol/mqtt_paho/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/eclipse/paho.golang/packets"
+	"github.com/eclipse/paho.golang/paho"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+)
+
+// MqttConfig selects the broker, topic and delivery options used by the MQTT
+// binding. Version 5 carries binary mode attributes as user properties while
+// version 3 only supports structured mode, so events are always sent whole.
+type MqttConfig struct {
+  Broker string `envconfig:"CE_MQTT_BROKER"`
+  Topic string `envconfig:"CE_MQTT_TOPIC" default:"cloudevents"`
+  QoS int `envconfig:"CE_MQTT_QOS" default:"0"`
+  Retain bool `envconfig:"CE_MQTT_RETAIN" default:"false"`
+  Version int `envconfig:"CE_MQTT_VERSION" default:"5"`
+  ClientID string `envconfig:"CE_MQTT_CLIENT_ID"`
+}
+
+func (config CloudEventConfig) mqttClient(ctx context.Context) (cloudevents.Client, error) {
+  mode, err := ParseMode(config.Mode)
+  if err != nil {
+    return nil, err
+  }
+  qos, err := config.mqttQoS()
+  if err != nil {
+    return nil, err
+  }
+
+  if config.Mqtt.Version == 3 {
+    sender, err := config.mqtt3(ctx, qos)
+    if err != nil {
+      return nil, Error(ErrSendFailed, fmt.Sprintf("Failed to connect to MQTT broker: %v", err))
+    }
+    return newClient(sender, binding.EncodingStructured, cloudevents.WithTimeNow(), cloudevents.WithUUIDs())
+  }
+
+  publish := &paho.Publish{Topic: config.Mqtt.Topic, QoS: qos, Retain: config.Mqtt.Retain}
+  sender, err := config.mqtt5(ctx, mqtt_paho.WithPublish(publish))
+  if err != nil {
+    return nil, Error(ErrSendFailed, fmt.Sprintf("Failed to connect to MQTT broker: %v", err))
+  }
+  return newClient(sender, mode, cloudevents.WithTimeNow(), cloudevents.WithUUIDs())
+}
+
+func (config CloudEventConfig) mqttReceiver(ctx context.Context) (cloudevents.Client, error) {
+  qos, err := config.mqttQoS()
+  if err != nil {
+    return nil, err
+  }
+
+  if config.Mqtt.Version == 3 {
+    consumer, err := config.mqtt3(ctx, qos)
+    if err != nil {
+      return nil, Error(ErrReceiveFailed, fmt.Sprintf("Failed to connect to MQTT broker: %v", err))
+    }
+    return newClient(consumer, binding.EncodingUnknown)
+  }
+
+  subscribe := &paho.Subscribe{
+    Subscriptions: []paho.SubscribeOptions{{Topic: config.Mqtt.Topic, QoS: qos}},
+  }
+  consumer, err := config.mqtt5(ctx, mqtt_paho.WithSubscribe(subscribe))
+  if err != nil {
+    return nil, Error(ErrReceiveFailed, fmt.Sprintf("Failed to connect to MQTT broker: %v", err))
+  }
+  return newClient(consumer, binding.EncodingUnknown)
+}
+
+func (config CloudEventConfig) mqtt5(ctx context.Context, option mqtt_paho.Option) (*mqtt_paho.Protocol, error) {
+  var conn net.Conn
+  var err error
+
+  if config.Insecure {
+    conn, err = net.Dial("tcp", config.mqttBroker())
+  } else {
+    if err := config.loadTLS(ctx, hostOf(config.mqttBroker())); err != nil {
+      return nil, err
+    }
+    conn, err = tls.Dial("tcp", config.mqttBroker(), config.Config)
+  }
+  if err != nil {
+    return nil, err
+  }
+
+  id := config.mqttClientID()
+  connect := &paho.Connect{ClientID: id, KeepAlive: 30, CleanStart: true}
+  settings := &paho.ClientConfig{ClientID: id, Conn: packets.NewThreadSafeConn(conn)}
+
+  return mqtt_paho.New(context.Background(), settings, mqtt_paho.WithConnect(connect), option)
+}
+
+func (config CloudEventConfig) mqtt3(ctx context.Context, qos byte) (*mqtt3Protocol, error) {
+  eventFormat, err := ParseFormat(config.Format)
+  if err != nil {
+    return nil, err
+  }
+
+  options := mqtt.NewClientOptions().SetClientID(config.mqttClientID()).SetOrderMatters(false)
+
+  if config.Insecure {
+    options.AddBroker("tcp://" + config.mqttBroker())
+  } else {
+    if err := config.loadTLS(ctx, hostOf(config.mqttBroker())); err != nil {
+      return nil, err
+    }
+    options.AddBroker("ssl://" + config.mqttBroker()).SetTLSConfig(config.Config)
+  }
+
+  client := mqtt.NewClient(options)
+  if token := client.Connect(); token.Wait() && token.Error() != nil {
+    return nil, token.Error()
+  }
+
+  return &mqtt3Protocol{
+    client:   client,
+    topic:    config.Mqtt.Topic,
+    qos:      qos,
+    retain:   config.Mqtt.Retain,
+    format:   eventFormat,
+    incoming: make(chan mqtt.Message),
+  }, nil
+}
+
+// mqttQoS checks the QoS is one MQTT defines before it is sent as a byte.
+func (config CloudEventConfig) mqttQoS() (byte, error) {
+  if config.Mqtt.QoS < 0 || config.Mqtt.QoS > 2 {
+    return 0, Error(ErrInvalidFormat, fmt.Sprintf("MQTT QoS must be 0, 1 or 2, not %d", config.Mqtt.QoS))
+  }
+  return byte(config.Mqtt.QoS), nil
+}
+
+// mqttBroker falls back to the configured address and port when no broker is set.
+func (config CloudEventConfig) mqttBroker() string {
+  if config.Mqtt.Broker != "" {
+    return config.Mqtt.Broker
+  }
+  return fmt.Sprintf("%s:%d", config.Address, config.Port)
+}
+
+func (config CloudEventConfig) mqttClientID() string {
+  if config.Mqtt.ClientID != "" {
+    return config.Mqtt.ClientID
+  }
+  return "cecli-" + uuid.NewString()[:8]
+}
+
+// MARK: - MQTT v3
+
+// mqtt3Protocol implements the structured content mode of the MQTT binding for
+// version 3.1.1 brokers, which have no user properties to carry attributes or
+// the content type, so both ends must agree on the event format.
+type mqtt3Protocol struct {
+  client   mqtt.Client
+  topic    string
+  qos      byte
+  retain   bool
+  format   format.Format
+  incoming chan mqtt.Message
+}
+
+func (protocol *mqtt3Protocol) Send(ctx context.Context, message binding.Message, transformers ...binding.Transformer) (err error) {
+  defer func() { _ = message.Finish(err) }()
+
+  event, err := binding.ToEvent(ctx, message, transformers...)
+  if err != nil {
+    return err
+  }
+
+  payload, err := protocol.format.Marshal(event)
+  if err != nil {
+    return err
+  }
+
+  token := protocol.client.Publish(protocol.topic, protocol.qos, protocol.retain, payload)
+  select {
+  case <-token.Done():
+    return token.Error()
+  case <-ctx.Done():
+    return ctx.Err()
+  }
+}
+
+func (protocol *mqtt3Protocol) OpenInbound(ctx context.Context) error {
+  token := protocol.client.Subscribe(protocol.topic, protocol.qos, func(_ mqtt.Client, message mqtt.Message) {
+    select {
+    case protocol.incoming <- message:
+    case <-ctx.Done():
+    }
+  })
+  if token.Wait() && token.Error() != nil {
+    return token.Error()
+  }
+
+  <-ctx.Done()
+  protocol.client.Unsubscribe(protocol.topic).Wait()
+  return nil
+}
+
+func (protocol *mqtt3Protocol) Receive(ctx context.Context) (binding.Message, error) {
+  for {
+    select {
+    case message := <-protocol.incoming:
+      event := cloudevents.NewEvent()
+      if err := protocol.format.Unmarshal(message.Payload(), &event); err != nil {
+        log.Printf("Dropping malformed MQTT message on %s: %v", message.Topic(), err)
+        continue
+      }
+      return binding.ToMessage(&event), nil
+    case <-ctx.Done():
+      return nil, io.EOF
+    }
+  }
+}
+
+func (protocol *mqtt3Protocol) Close(ctx context.Context) error {
+  protocol.client.Disconnect(250)
+  return nil
+}
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+)
+
+// startMqtt runs an in-process MQTT broker accepting v3.1.1 and v5 clients.
+func startMqtt(t *testing.T) (*mochi.Server, string) {
+  t.Helper()
+
+  broker := mochi.New(&mochi.Options{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+  if err := broker.AddHook(new(auth.AllowHook), nil); err != nil {
+    t.Fatal(err)
+  }
+  listener := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+  if err := broker.AddListener(listener); err != nil {
+    t.Fatal(err)
+  }
+  if err := broker.Serve(); err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() { _ = broker.Close() })
+  return broker, listener.Address()
+}
+
+func TestMqttPublishSubscribe(t *testing.T) {
+  for _, version := range []int{3, 5} {
+    t.Run("v"+strconv.Itoa(version), func(t *testing.T) {
+      broker, address := startMqtt(t)
+      config := &CloudEventConfig{
+        Protocol: ProtocolMQTT,
+        Insecure: true,
+        Mqtt:     MqttConfig{Broker: address, Topic: "orders", QoS: 1, Version: version, ClientID: "cecli-listener"},
+      }
+
+      received := make(chan cloudevents.Event, 1)
+      listen(t, config, func(ctx context.Context, event cloudevents.Event) error {
+        received <- event
+        return nil
+      })
+      waitFor(t, "the subscription", func() bool {
+        _, ok := broker.Topics.Subscribers("orders").Subscriptions["cecli-listener"]
+        return ok
+      })
+      if subscription := broker.Topics.Subscribers("orders").Subscriptions["cecli-listener"]; subscription.Qos != 1 {
+        t.Errorf("subscribed with QoS %d, want 1", subscription.Qos)
+      }
+
+      sender := *config
+      sender.Mqtt.ClientID = "cecli-sender"
+      send(t, &sender, "order-"+strconv.Itoa(version))
+
+      select {
+      case event := <-received:
+        if event.ID() != "order-"+strconv.Itoa(version) || event.Type() != "com.example.order" {
+          t.Errorf("received %s %s, want order-%d com.example.order", event.ID(), event.Type(), version)
+        }
+      case <-time.After(10 * time.Second):
+        t.Fatal("no event received")
+      }
+    })
+  }
+}
+
+func TestMqttRejectsQoSOutsideTheSpec(t *testing.T) {
+  for _, qos := range []int{-1, 3, 256} {
+    for _, version := range []int{3, 5} {
+      // The QoS is checked before dialing, so no broker is needed
+      config := CloudEventConfig{
+        Protocol: ProtocolMQTT,
+        Insecure: true,
+        Mqtt:     MqttConfig{Broker: "127.0.0.1:1", Topic: "orders", QoS: qos, Version: version},
+      }
+
+      _, err := config.Client(t.Context())
+      var cloudEventErr *CloudEventError
+      if !errors.As(err, &cloudEventErr) || cloudEventErr.Code != ErrInvalidFormat {
+        t.Errorf("v%d Client with QoS %d: err = %v, want ErrInvalidFormat", version, qos, err)
+      }
+
+      _, err = config.Receiver(t.Context())
+      if !errors.As(err, &cloudEventErr) || cloudEventErr.Code != ErrInvalidFormat {
+        t.Errorf("v%d Receiver with QoS %d: err = %v, want ErrInvalidFormat", version, qos, err)
+      }
+    }
+  }
+}
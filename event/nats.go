@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	cenats "github.com/cloudevents/sdk-go/protocol/nats/v2"
+	"github.com/cloudevents/sdk-go/protocol/nats_jetstream/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/nats-io/nats.go"
+)
+
+// NatsConfig selects the server, subject and consumer settings used by the
+// NATS and JetStream bindings. JetStream subjects must live under Stream.
+type NatsConfig struct {
+  Url string `envconfig:"CE_NATS_URL"`
+  Subject string `envconfig:"CE_NATS_SUBJECT" default:"cloudevents"`
+  Queue string `envconfig:"CE_NATS_QUEUE"`
+  Stream string `envconfig:"CE_NATS_STREAM" default:"cloudevents"`
+  Durable string `envconfig:"CE_NATS_DURABLE"`
+}
+
+func (config CloudEventConfig) natsClient(ctx context.Context) (cloudevents.Client, error) {
+  mode, err := ParseMode(config.Mode)
+  if err != nil {
+    return nil, err
+  }
+
+  options, err := config.natsOptions(ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  var sender interface{}
+  if config.isJetStream() {
+    sender, err = nats_jetstream.NewSender(config.natsUrl(), config.Nats.Stream, config.Nats.Subject, options, nil)
+  } else {
+    sender, err = cenats.NewSender(config.natsUrl(), config.Nats.Subject, options)
+  }
+  if err != nil {
+    return nil, Error(ErrSendFailed, fmt.Sprintf("Failed to create NATS sender: %v", err))
+  }
+  return newClient(sender, mode, cloudevents.WithTimeNow(), cloudevents.WithUUIDs())
+}
+
+func (config CloudEventConfig) natsReceiver(ctx context.Context) (cloudevents.Client, error) {
+  options, err := config.natsOptions(ctx)
+  if err != nil {
+    return nil, err
+  }
+
+  if !config.isJetStream() {
+    consumerOptions := []cenats.ConsumerOption{}
+    if config.Nats.Queue != "" {
+      consumerOptions = append(consumerOptions, cenats.WithQueueSubscriber(config.Nats.Queue))
+    }
+
+    consumer, err := cenats.NewConsumer(config.natsUrl(), config.Nats.Subject, options, consumerOptions...)
+    if err != nil {
+      return nil, Error(ErrReceiveFailed, fmt.Sprintf("Failed to create NATS consumer: %v", err))
+    }
+    return newClient(consumer, binding.EncodingUnknown)
+  }
+
+  // Acknowledge explicitly so the callback result decides between ack and nak
+  subscribe := []nats.SubOpt{nats.ManualAck(), nats.AckExplicit()}
+  if config.Nats.Durable != "" {
+    subscribe = append(subscribe, nats.Durable(config.Nats.Durable))
+  }
+
+  consumerOptions := []nats_jetstream.ConsumerOption{}
+  if config.Nats.Queue != "" {
+    consumerOptions = append(consumerOptions, nats_jetstream.WithQueueSubscriber(config.Nats.Queue))
+  }
+
+  consumer, err := nats_jetstream.NewConsumer(config.natsUrl(), config.Nats.Stream, config.Nats.Subject, options, nil, subscribe, consumerOptions...)
+  if err != nil {
+    return nil, Error(ErrReceiveFailed, fmt.Sprintf("Failed to create JetStream consumer: %v", err))
+  }
+  return newClient(&jetstreamConsumer{consumer}, binding.EncodingUnknown)
+}
+
+func (config CloudEventConfig) natsOptions(ctx context.Context) ([]nats.Option, error) {
+  options := []nats.Option{nats.Name("cecli")}
+  if !config.Insecure {
+    if err := config.loadTLS(ctx, urlHosts(config.natsUrl())...); err != nil {
+      return nil, err
+    }
+    options = append(options, nats.Secure(config.Config))
+  }
+  return options, nil
+}
+
+func (config CloudEventConfig) isJetStream() bool {
+  return config.protocol() == ProtocolJetStream
+}
+
+// natsUrl falls back to the configured address and port when no URL is set.
+func (config CloudEventConfig) natsUrl() string {
+  if config.Nats.Url != "" {
+    return config.Nats.Url
+  }
+  return fmt.Sprintf("nats://%s:%d", config.Address, config.Port)
+}
+
+// MARK: - JetStream
+
+// jetstreamConsumer settles each JetStream message according to the callback
+// result: ack on success, nak so the server redelivers on failure.
+type jetstreamConsumer struct {
+  *nats_jetstream.Consumer
+}
+
+func (consumer *jetstreamConsumer) Receive(ctx context.Context) (binding.Message, error) {
+  message, err := consumer.Consumer.Receive(ctx)
+  if err != nil {
+    return nil, err
+  }
+  if jetstream, ok := message.(*nats_jetstream.Message); ok {
+    return &jetstreamMessage{jetstream}, nil
+  }
+  return message, nil
+}
+
+type jetstreamMessage struct {
+  *nats_jetstream.Message
+}
+
+func (message *jetstreamMessage) Finish(result error) error {
+  if protocol.IsACK(result) {
+    return message.Msg.Ack()
+  }
+
+  log.Printf("Negatively acknowledging JetStream message: %v", result)
+  return message.Msg.Nak()
+}
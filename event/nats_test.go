@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+
+	api "github.com/anselmes/ce-go-template/api/v1"
+)
+
+// startNats runs an in-process NATS server with JetStream enabled.
+func startNats(t *testing.T) *server.Server {
+  t.Helper()
+
+  broker, err := server.NewServer(&server.Options{
+    Host:      "127.0.0.1",
+    Port:      server.RANDOM_PORT,
+    JetStream: true,
+    StoreDir:  t.TempDir(),
+    NoLog:     true,
+    NoSigs:    true,
+  })
+  if err != nil {
+    t.Fatal(err)
+  }
+  go broker.Start()
+  if !broker.ReadyForConnections(5 * time.Second) {
+    t.Fatal("NATS server did not start")
+  }
+  t.Cleanup(broker.Shutdown)
+  return broker
+}
+
+// listen runs Listen until the test ends.
+func listen(t *testing.T, config *CloudEventConfig, callback callback) {
+  t.Helper()
+
+  ctx, cancel := context.WithCancel(context.Background())
+  done := make(chan error, 1)
+  go func() {
+    done <- NewCloudEventManager(&api.Data{}, nil).Listen(ctx, config, callback)
+  }()
+  t.Cleanup(func() {
+    cancel()
+    <-done
+  })
+}
+
+// send delivers one event with the given id through the configured client.
+func send(t *testing.T, config *CloudEventConfig, id string) {
+  t.Helper()
+
+  client, err := config.Client(t.Context())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer func() { _ = Close(context.Background(), client) }()
+  manager := NewCloudEventManager(&api.Data{Message: "created"}, &CloudEventOptions{Source: "/orders", Type: "com.example.order", ID: id})
+  manager.SetRetry(1)
+  if _, err := manager.Send(context.Background(), client); err != nil {
+    t.Fatalf("Send: %v", err)
+  }
+}
+
+// waitFor polls until check succeeds.
+func waitFor(t *testing.T, what string, check func() bool) {
+  t.Helper()
+
+  deadline := time.Now().Add(10 * time.Second)
+  for !check() {
+    if time.Now().After(deadline) {
+      t.Fatalf("timed out waiting for %s", what)
+    }
+    time.Sleep(20 * time.Millisecond)
+  }
+}
+
+func TestNatsPublishSubscribe(t *testing.T) {
+  broker := startNats(t)
+  config := &CloudEventConfig{
+    Protocol: ProtocolNATS,
+    Insecure: true,
+    Nats:     NatsConfig{Url: broker.ClientURL(), Subject: "orders.created"},
+  }
+
+  received := make(chan cloudevents.Event, 1)
+  listen(t, config, func(ctx context.Context, event cloudevents.Event) error {
+    received <- event
+    return nil
+  })
+  waitFor(t, "the subscription", func() bool { return broker.GlobalAccount().SubscriptionInterest("orders.created") })
+
+  send(t, config, "order-1")
+
+  select {
+  case event := <-received:
+    if event.ID() != "order-1" || event.Type() != "com.example.order" {
+      t.Errorf("received %s %s, want order-1 com.example.order", event.ID(), event.Type())
+    }
+  case <-time.After(10 * time.Second):
+    t.Fatal("no event received")
+  }
+}
+
+func TestJetStreamAcksOnSuccessAndRedeliversOnError(t *testing.T) {
+  broker := startNats(t)
+  config := &CloudEventConfig{
+    Protocol: ProtocolJetStream,
+    Insecure: true,
+    Nats: NatsConfig{
+      Url:     broker.ClientURL(),
+      Stream:  "orders",
+      Subject: "orders.created",
+      Durable: "cecli-test",
+    },
+  }
+
+  // The sender creates the stream the consumer binds to
+  send(t, config, "order-2")
+
+  var mu sync.Mutex
+  var deliveries []string
+  listen(t, config, func(ctx context.Context, event cloudevents.Event) error {
+    mu.Lock()
+    defer mu.Unlock()
+    deliveries = append(deliveries, event.ID())
+    if len(deliveries) == 1 {
+      return errors.New("first delivery fails")
+    }
+    return nil
+  })
+
+  connection, err := nats.Connect(broker.ClientURL())
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer connection.Close()
+  jetstream, err := connection.JetStream()
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  // Nak redelivers the message, the second delivery is acked
+  waitFor(t, "the redelivered message to be acked", func() bool {
+    info, err := jetstream.ConsumerInfo("orders", "cecli-test")
+    return err == nil && info.Delivered.Consumer == 2 && info.AckFloor.Consumer == 2 && info.NumAckPending == 0
+  })
+
+  mu.Lock()
+  defer mu.Unlock()
+  if len(deliveries) != 2 || deliveries[0] != "order-2" || deliveries[1] != "order-2" {
+    t.Errorf("deliveries = %v, want order-2 twice", deliveries)
+  }
+}
@@ -3,10 +3,25 @@
 
 package cloudevent
 
-import api "github.com/anselmes/ce-go-template/api/v1"
+import (
+	"time"
 
+	api "github.com/anselmes/ce-go-template/api/v1"
+)
+
+// CloudEventOptions overrides the attributes of the outgoing event. Zero
+// values keep the defaults: a random id, no subject or time, and the content
+// type and schema of the encoded data.
 type CloudEventOptions struct {
   Source string
   Type   string
   Data api.Data
+
+  ID string
+  Subject string
+  Time time.Time
+  DataSchema string
+  DataContentType string
+  SpecVersion string
+  Extensions map[string]string
 }
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// ReadPayload resolves a data argument: @path reads a file, @- reads stdin
+// and anything else is the payload itself. The content type suggested by the
+// file extension is returned along with it.
+func ReadPayload(data string) ([]byte, string, error) {
+  if !strings.HasPrefix(data, "@") {
+    return []byte(data), "", nil
+  }
+  return ReadPayloadFile(strings.TrimPrefix(data, "@"))
+}
+
+// ReadPayloadFile reads a payload from a file, or stdin for -.
+func ReadPayloadFile(path string) ([]byte, string, error) {
+  var payload []byte
+  var err error
+  if path == "-" {
+    payload, err = io.ReadAll(os.Stdin)
+  } else {
+    payload, err = os.ReadFile(path)
+  }
+  if err != nil {
+    return nil, "", Wrap(ErrInvalidFormat, err)
+  }
+  return payload, mime.TypeByExtension(filepath.Ext(path)), nil
+}
+
+// DetectContentType guesses the media type of a payload: JSON, XML, plain
+// text, or opaque bytes.
+func DetectContentType(payload []byte) string {
+  trimmed := bytes.TrimSpace(payload)
+  switch {
+  case len(trimmed) > 0 && json.Valid(trimmed):
+    return cloudevents.ApplicationJSON
+  case bytes.HasPrefix(trimmed, []byte("<")) && isXML(trimmed):
+    return cloudevents.ApplicationXML
+  case utf8.Valid(payload):
+    return cloudevents.TextPlain
+  default:
+    return "application/octet-stream"
+  }
+}
+
+func isXML(payload []byte) bool {
+  decoder := xml.NewDecoder(bytes.NewReader(payload))
+  for {
+    if _, err := decoder.Token(); err != nil {
+      return errors.Is(err, io.EOF)
+    }
+  }
+}
+
+// isJSON reports whether a media type is JSON, including +json suffixes.
+func isJSON(contentType string) bool {
+  media, _, err := mime.ParseMediaType(contentType)
+  if err != nil {
+    return false
+  }
+  return media == cloudevents.ApplicationJSON || media == "text/json" || strings.HasSuffix(media, "+json")
+}
+
+// isText reports whether a media type can be carried as a string, so
+// structured JSON events embed it instead of using data_base64.
+func isText(contentType string) bool {
+  media, _, err := mime.ParseMediaType(contentType)
+  if err != nil {
+    return false
+  }
+  return isJSON(contentType) || strings.HasPrefix(media, "text/") || media == cloudevents.ApplicationXML || strings.HasSuffix(media, "+xml")
+}
+
+// SetPayload attaches the payload unchanged. Without a content type one is
+// detected, and payloads declared as JSON must be valid JSON.
+func (manager *CloudEventManager) SetPayload(payload []byte, contentType string) error {
+  if contentType == "" {
+    contentType = DetectContentType(payload)
+  }
+  if isJSON(contentType) && !json.Valid(payload) {
+    return Error(ErrInvalidFormat, fmt.Sprintf("data is not valid %s", contentType))
+  }
+
+  manager.payload = payload
+  manager.contentType = contentType
+  manager.message = nil
+  return manager.setData()
+}
+
+// DecodePayload strictly decodes the payload into the registered message
+// with the given full name, rejecting unknown fields. Protobuf payloads are
+// read in the binary wire format and anything else as protobuf JSON.
+func (manager *CloudEventManager) DecodePayload(payload []byte, contentType string, name string) error {
+  message, err := NewMessage(name)
+  if err != nil {
+    return err
+  }
+
+  if strings.HasPrefix(contentType, protobuf.ContentTypeProtobuf) {
+    err = proto.Unmarshal(payload, message)
+  } else {
+    err = protojson.Unmarshal(payload, message)
+  }
+  if err != nil {
+    return Error(ErrInvalidFormat, fmt.Sprintf("data is not a valid %s: %v", name, err))
+  }
+
+  manager.payload = nil
+  manager.message = message
+  return manager.setData()
+}
+
+// NewMessage creates an empty instance of a registered message.
+func NewMessage(name string) (proto.Message, error) {
+  messageType, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(name))
+  if err != nil {
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("unknown message %q: %v", name, err))
+  }
+  return messageType.New().Interface(), nil
+}
@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+
+	api "github.com/anselmes/ce-go-template/api/v1"
+)
+
+func TestSetPayloadRoundTrips(t *testing.T) {
+  payloads := []struct {
+    name        string
+    payload     []byte
+    contentType string
+  }{
+    {"text", []byte("plain text"), cloudevents.TextPlain},
+    {"xml", []byte("<order><id>42</id></order>"), cloudevents.ApplicationXML},
+    {"binary", []byte{0x89, 'P', 'N', 'G', 0x00, 0xff, 0xfe}, "application/octet-stream"},
+    {"json", []byte(`{"message":"value"}`), cloudevents.ApplicationJSON},
+  }
+
+  for _, version := range []string{cloudevents.VersionV03, cloudevents.VersionV1} {
+    for _, test := range payloads {
+      t.Run(version+"/"+test.name, func(t *testing.T) {
+        manager := NewCloudEventManager(&api.Data{}, &CloudEventOptions{SpecVersion: version})
+        if err := manager.SetPayload(test.payload, ""); err != nil {
+          t.Fatalf("SetPayload: %v", err)
+        }
+        if manager.Event.DataContentType() != test.contentType {
+          t.Errorf("datacontenttype = %q, want %q", manager.Event.DataContentType(), test.contentType)
+        }
+        if !bytes.Equal(manager.Event.Data(), test.payload) {
+          t.Errorf("data = %q, want %q", manager.Event.Data(), test.payload)
+        }
+
+        encoded, err := json.Marshal(manager.Event)
+        if err != nil {
+          t.Fatal(err)
+        }
+        decoded := cloudevents.NewEvent()
+        if err := json.Unmarshal(encoded, &decoded); err != nil {
+          t.Fatalf("decoding %s: %v", encoded, err)
+        }
+        if decoded.SpecVersion() != version {
+          t.Errorf("specversion = %q, want %q", decoded.SpecVersion(), version)
+        }
+        if !bytes.Equal(decoded.Data(), test.payload) {
+          t.Errorf("data after a round trip through %s = %q, want %q", encoded, decoded.Data(), test.payload)
+        }
+      })
+    }
+  }
+}
+
+func TestDecodePayloadOnLegacySpecVersion(t *testing.T) {
+  manager := NewCloudEventManager(&api.Data{}, &CloudEventOptions{SpecVersion: cloudevents.VersionV03})
+  if err := manager.DecodePayload([]byte(`{"message":"value"}`), cloudevents.ApplicationJSON, "api.Data"); err != nil {
+    t.Fatalf("DecodePayload: %v", err)
+  }
+
+  var decoded api.Data
+  if err := manager.Event.DataAs(&decoded); err != nil {
+    t.Fatalf("data %q is not JSON: %v", manager.Event.Data(), err)
+  }
+  if decoded.Message != "value" {
+    t.Errorf("message = %q, want value", decoded.Message)
+  }
+}
+
+func TestProtobufFormatCarriesDataAsProtoData(t *testing.T) {
+  protobufFormat, err := ParseFormat("protobuf")
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  tests := []struct {
+    payload     string
+    contentType string
+  }{
+    {`{"message":"value"}`, "application/protobuf"},
+    {`{"order":42}`, cloudevents.ApplicationJSON},
+  }
+  for _, test := range tests {
+    manager := NewCloudEventManager(&api.Data{}, nil)
+    if err := manager.SetFormat(protobufFormat); err != nil {
+      t.Fatal(err)
+    }
+    if err := manager.SetPayload([]byte(test.payload), ""); err != nil {
+      t.Fatalf("SetPayload: %v", err)
+    }
+    if manager.Event.DataContentType() != test.contentType {
+      t.Errorf("%s: datacontenttype = %q, want %q", test.payload, manager.Event.DataContentType(), test.contentType)
+    }
+    if _, err := manager.Encode(); err != nil {
+      t.Errorf("%s: Encode: %v", test.payload, err)
+    }
+  }
+}
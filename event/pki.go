@@ -0,0 +1,405 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// backdate tolerates clock skew between the issuer and relying parties.
+const backdate = 5 * time.Minute
+
+// CertConfig mirrors cert.yaml: cfssl signing profiles and the requests for
+// the root CA, the intermediate CA and the TLS certificate.
+type CertConfig struct {
+  Config struct {
+    Signing SigningConfig `yaml:"signing"`
+  } `yaml:"config"`
+  CA CertRequest `yaml:"ca"`
+  Intermediate CertRequest `yaml:"intermediate"`
+  TLS CertRequest `yaml:"tls"`
+}
+
+type SigningConfig struct {
+  Default SigningProfile `yaml:"default"`
+  Profiles map[string]SigningProfile `yaml:"profiles"`
+}
+
+type SigningProfile struct {
+  Expiry string `yaml:"expiry"`
+  Usages []string `yaml:"usages"`
+  CAConstraint struct {
+    IsCA bool `yaml:"is_ca"`
+    MaxPathLen int `yaml:"max_path_len"`
+    MaxPathLenZero bool `yaml:"max_path_len_zero"`
+  } `yaml:"ca_constraint"`
+}
+
+type CertRequest struct {
+  CN string `yaml:"CN"`
+  Key KeyRequest `yaml:"key"`
+  Names []CertName `yaml:"names"`
+  Hosts []string `yaml:"hosts"`
+}
+
+type KeyRequest struct {
+  Algo string `yaml:"algo"`
+  Size int `yaml:"size"`
+}
+
+type CertName struct {
+  C string `yaml:"C"`
+  L string `yaml:"L"`
+  ST string `yaml:"ST"`
+  O string `yaml:"O"`
+  OU string `yaml:"OU"`
+}
+
+// KeyPair is a certificate with its private key.
+type KeyPair struct {
+  Certificate *x509.Certificate
+  Key crypto.Signer
+}
+
+var keyUsages = map[string]x509.KeyUsage{
+  "signing":            x509.KeyUsageDigitalSignature,
+  "digital signature":  x509.KeyUsageDigitalSignature,
+  "content commitment": x509.KeyUsageContentCommitment,
+  "key encipherment":   x509.KeyUsageKeyEncipherment,
+  "key agreement":      x509.KeyUsageKeyAgreement,
+  "data encipherment":  x509.KeyUsageDataEncipherment,
+  "cert sign":          x509.KeyUsageCertSign,
+  "crl sign":           x509.KeyUsageCRLSign,
+  "encipher only":      x509.KeyUsageEncipherOnly,
+  "decipher only":      x509.KeyUsageDecipherOnly,
+}
+
+var extKeyUsages = map[string]x509.ExtKeyUsage{
+  "any":              x509.ExtKeyUsageAny,
+  "server auth":      x509.ExtKeyUsageServerAuth,
+  "client auth":      x509.ExtKeyUsageClientAuth,
+  "code signing":     x509.ExtKeyUsageCodeSigning,
+  "email protection": x509.ExtKeyUsageEmailProtection,
+  "s/mime":           x509.ExtKeyUsageEmailProtection,
+  "ipsec end system": x509.ExtKeyUsageIPSECEndSystem,
+  "ipsec tunnel":     x509.ExtKeyUsageIPSECTunnel,
+  "ipsec user":       x509.ExtKeyUsageIPSECUser,
+  "timestamping":     x509.ExtKeyUsageTimeStamping,
+  "ocsp signing":     x509.ExtKeyUsageOCSPSigning,
+}
+
+// LoadCertConfig reads the certificate profiles and requests from cert.yaml.
+func LoadCertConfig(path string) (*CertConfig, error) {
+  content, err := os.ReadFile(path)
+  if err != nil {
+    return nil, Wrap(ErrTlsConfig, err)
+  }
+
+  config := &CertConfig{}
+  if err := yaml.Unmarshal(content, config); err != nil {
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("%s: %v", path, err))
+  }
+  return config, nil
+}
+
+// Profile returns the named signing profile, inheriting the default expiry.
+func (config *CertConfig) Profile(name string) (SigningProfile, error) {
+  profile, ok := config.Config.Signing.Profiles[name]
+  if !ok {
+    return SigningProfile{}, Error(ErrTlsConfig, fmt.Sprintf("unknown signing profile %q", name))
+  }
+  if profile.Expiry == "" {
+    profile.Expiry = config.Config.Signing.Default.Expiry
+  }
+  return profile, nil
+}
+
+// InitCA creates a self-signed root CA from the request and profile.
+func InitCA(request CertRequest, profile SigningProfile) (*KeyPair, error) {
+  return issue(request, profile, nil)
+}
+
+// Sign issues a certificate for the request, signed by the parent CA.
+func Sign(request CertRequest, profile SigningProfile, parent *KeyPair) (*KeyPair, error) {
+  if parent == nil {
+    return nil, Error(ErrTlsConfig, "no signing CA given")
+  }
+  return issue(request, profile, parent)
+}
+
+func issue(request CertRequest, profile SigningProfile, parent *KeyPair) (*KeyPair, error) {
+  key, err := request.Key.generate()
+  if err != nil {
+    return nil, err
+  }
+
+  template, err := profile.template(request, parent)
+  if err != nil {
+    return nil, err
+  }
+
+  // Self-signed when there is no parent
+  issuer, signer := template, crypto.Signer(key)
+  if parent != nil {
+    issuer, signer = parent.Certificate, parent.Key
+  }
+
+  der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), signer)
+  if err != nil {
+    return nil, Wrap(ErrTlsConfig, err)
+  }
+
+  cert, err := x509.ParseCertificate(der)
+  if err != nil {
+    return nil, Wrap(ErrTlsConfig, err)
+  }
+  return &KeyPair{Certificate: cert, Key: key}, nil
+}
+
+func (profile SigningProfile) template(request CertRequest, parent *KeyPair) (*x509.Certificate, error) {
+  expiry, err := time.ParseDuration(profile.Expiry)
+  if err != nil || expiry <= 0 {
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("invalid expiry %q", profile.Expiry))
+  }
+
+  serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 159))
+  if err != nil {
+    return nil, Wrap(ErrTlsConfig, err)
+  }
+
+  now := time.Now()
+  template := &x509.Certificate{
+    SerialNumber:          serial,
+    Subject:               request.subject(),
+    NotBefore:             now.Add(-backdate),
+    NotAfter:              now.Add(expiry),
+    BasicConstraintsValid: true,
+    IsCA:                  profile.CAConstraint.IsCA,
+  }
+  // A certificate cannot outlive its issuer
+  if parent != nil && template.NotAfter.After(parent.Certificate.NotAfter) {
+    template.NotAfter = parent.Certificate.NotAfter
+  }
+  if template.IsCA {
+    template.MaxPathLen = profile.CAConstraint.MaxPathLen
+    template.MaxPathLenZero = profile.CAConstraint.MaxPathLenZero
+    if template.MaxPathLen == 0 && !template.MaxPathLenZero {
+      template.MaxPathLen = -1
+    }
+  }
+
+  for _, usage := range profile.Usages {
+    name := strings.ToLower(strings.TrimSpace(usage))
+    if usage, ok := keyUsages[name]; ok {
+      template.KeyUsage |= usage
+    } else if usage, ok := extKeyUsages[name]; ok {
+      template.ExtKeyUsage = append(template.ExtKeyUsage, usage)
+    } else {
+      return nil, Error(ErrInvalidFormat, fmt.Sprintf("unknown usage %q", usage))
+    }
+  }
+
+  for _, host := range request.Hosts {
+    if ip := net.ParseIP(host); ip != nil {
+      template.IPAddresses = append(template.IPAddresses, ip)
+    } else if uri, err := url.Parse(host); err == nil && uri.Scheme != "" && uri.Host != "" {
+      template.URIs = append(template.URIs, uri)
+    } else if strings.Contains(host, "@") {
+      template.EmailAddresses = append(template.EmailAddresses, host)
+    } else {
+      template.DNSNames = append(template.DNSNames, host)
+    }
+  }
+  return template, nil
+}
+
+func (request CertRequest) subject() pkix.Name {
+  subject := pkix.Name{CommonName: request.CN}
+  for _, name := range request.Names {
+    subject.Country = appendNonEmpty(subject.Country, name.C)
+    subject.Locality = appendNonEmpty(subject.Locality, name.L)
+    subject.Province = appendNonEmpty(subject.Province, name.ST)
+    subject.Organization = appendNonEmpty(subject.Organization, name.O)
+    subject.OrganizationalUnit = appendNonEmpty(subject.OrganizationalUnit, name.OU)
+  }
+  return subject
+}
+
+func appendNonEmpty(values []string, value string) []string {
+  if value == "" {
+    return values
+  }
+  return append(values, value)
+}
+
+// generate creates an RSA or ECDSA key, defaulting to ECDSA P-256 like cfssl.
+func (request KeyRequest) generate() (crypto.Signer, error) {
+  switch strings.ToLower(request.Algo) {
+  case "rsa":
+    size := request.Size
+    if size == 0 {
+      size = 2048
+    }
+    if size < 2048 {
+      return nil, Error(ErrInvalidFormat, fmt.Sprintf("RSA key size %d is below 2048", size))
+    }
+    return rsa.GenerateKey(rand.Reader, size)
+  case "", "ecdsa":
+    switch request.Size {
+    case 0, 256:
+      return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    case 384:
+      return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+    case 521:
+      return ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+    default:
+      return nil, Error(ErrInvalidFormat, fmt.Sprintf("unsupported ECDSA key size %d", request.Size))
+    }
+  default:
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("unsupported key algorithm %q", request.Algo))
+  }
+}
+
+// MARK: - Files
+
+// LoadKeyPair reads a PEM certificate and private key, such as ca.pem and
+// ca-key.pem.
+func LoadKeyPair(certFile string, keyFile string) (*KeyPair, error) {
+  certs, err := ReadCertificates(certFile)
+  if err != nil {
+    return nil, err
+  }
+
+  content, err := os.ReadFile(keyFile)
+  if err != nil {
+    return nil, Wrap(ErrTlsConfig, err)
+  }
+  block, _ := pem.Decode(content)
+  if block == nil {
+    return nil, Error(ErrTlsConfig, fmt.Sprintf("no private key found in %s", keyFile))
+  }
+
+  var key interface{}
+  switch block.Type {
+  case "RSA PRIVATE KEY":
+    key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+  case "EC PRIVATE KEY":
+    key, err = x509.ParseECPrivateKey(block.Bytes)
+  default:
+    key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+  }
+  if err != nil {
+    return nil, Error(ErrTlsConfig, fmt.Sprintf("%s: %v", keyFile, err))
+  }
+
+  signer, ok := key.(crypto.Signer)
+  if !ok {
+    return nil, Error(ErrTlsConfig, fmt.Sprintf("%s: unsupported private key", keyFile))
+  }
+  return &KeyPair{Certificate: certs[0], Key: signer}, nil
+}
+
+// ReadCertificates parses every certificate of a PEM bundle, in file order.
+func ReadCertificates(file string) ([]*x509.Certificate, error) {
+  content, err := os.ReadFile(file)
+  if err != nil {
+    return nil, Wrap(ErrTlsConfig, err)
+  }
+
+  var certs []*x509.Certificate
+  for block, rest := pem.Decode(content); block != nil; block, rest = pem.Decode(rest) {
+    if block.Type != "CERTIFICATE" {
+      continue
+    }
+    cert, err := x509.ParseCertificate(block.Bytes)
+    if err != nil {
+      return nil, Error(ErrTlsConfig, fmt.Sprintf("%s: %v", file, err))
+    }
+    certs = append(certs, cert)
+  }
+
+  if len(certs) == 0 {
+    return nil, Error(ErrTlsConfig, fmt.Sprintf("no certificates found in %s", file))
+  }
+  return certs, nil
+}
+
+// Write stores the pair using the cfssljson -bare layout: <name>.pem and
+// <name>-key.pem in dir.
+func (pair *KeyPair) Write(dir string, name string) error {
+  key, err := encodeKey(pair.Key)
+  if err != nil {
+    return err
+  }
+
+  if err := writePEM(filepath.Join(dir, name+"-key.pem"), 0o600, key); err != nil {
+    return err
+  }
+  return writePEM(filepath.Join(dir, name+".pem"), 0o644, pair.pem())
+}
+
+func (pair *KeyPair) pem() *pem.Block {
+  return &pem.Block{Type: "CERTIFICATE", Bytes: pair.Certificate.Raw}
+}
+
+// WriteBundle concatenates certificate files, leaf first, into file.
+func WriteBundle(file string, parts ...string) error {
+  var bundle []byte
+  for _, part := range parts {
+    content, err := os.ReadFile(part)
+    if err != nil {
+      return Wrap(ErrTlsConfig, err)
+    }
+    bundle = append(bundle, content...)
+  }
+
+  if err := os.WriteFile(file, bundle, 0o644); err != nil {
+    return Wrap(ErrTlsConfig, err)
+  }
+  return nil
+}
+
+// encodeKey uses the same PEM types as cfssl for RSA and ECDSA keys.
+func encodeKey(key crypto.Signer) (*pem.Block, error) {
+  switch key := key.(type) {
+  case *rsa.PrivateKey:
+    return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}, nil
+  case *ecdsa.PrivateKey:
+    der, err := x509.MarshalECPrivateKey(key)
+    if err != nil {
+      return nil, Wrap(ErrTlsConfig, err)
+    }
+    return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, nil
+  default:
+    return nil, Error(ErrTlsConfig, fmt.Sprintf("unsupported private key %T", key))
+  }
+}
+
+func writePEM(file string, mode os.FileMode, block *pem.Block) error {
+  if err := os.WriteFile(file, pem.EncodeToMemory(block), mode); err != nil {
+    return Wrap(ErrTlsConfig, err)
+  }
+  // WriteFile keeps the mode of a file that already exists
+  if err := os.Chmod(file, mode); err != nil {
+    return Wrap(ErrTlsConfig, err)
+  }
+  return nil
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import "testing"
+
+func TestSignCapsExpiryAtIssuer(t *testing.T) {
+  key := KeyRequest{Algo: "ecdsa", Size: 256}
+  ca := SigningProfile{Expiry: "24h", Usages: []string{"cert sign"}}
+  ca.CAConstraint.IsCA = true
+
+  root, err := InitCA(CertRequest{CN: "root", Key: key}, ca)
+  if err != nil {
+    t.Fatalf("InitCA: %v", err)
+  }
+
+  leaf, err := Sign(CertRequest{CN: "leaf", Key: key, Hosts: []string{"localhost"}}, SigningProfile{Expiry: "8760h", Usages: []string{"server auth"}}, root)
+  if err != nil {
+    t.Fatalf("Sign: %v", err)
+  }
+  if !leaf.Certificate.NotAfter.Equal(root.Certificate.NotAfter) {
+    t.Errorf("NotAfter = %s, want the issuer's %s", leaf.Certificate.NotAfter, root.Certificate.NotAfter)
+  }
+  if err := leaf.Certificate.CheckSignatureFrom(root.Certificate); err != nil {
+    t.Errorf("leaf is not signed by the root: %v", err)
+  }
+}
@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	api "github.com/anselmes/ce-go-template/api/v1"
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+  UnknownTypeReject = "reject"
+  UnknownTypePassThrough = "pass-through"
+  UnknownTypeWarn = "warn"
+)
+
+// TypeData is the CloudEvents type of events carrying api.Data.
+const TypeData = "cecli.v1.data"
+
+// Types maps the CloudEvents types of api/v1 to their data messages.
+var Types = NewRegistry()
+
+func init() {
+  Types.Register(TypeData, &api.Data{})
+}
+
+// Registry maps CloudEvents type values to the protobuf message carried as
+// their data.
+type Registry struct {
+  mu sync.RWMutex
+  types map[string]protoreflect.MessageType
+}
+
+// RegistryEntry is one registered type, for listings.
+type RegistryEntry struct {
+  Type string
+  Message string
+  DataSchema string
+}
+
+type messageKey struct{}
+
+func NewRegistry() *Registry {
+  return &Registry{types: map[string]protoreflect.MessageType{}}
+}
+
+// Register maps an event type to the message type of the given message.
+func (registry *Registry) Register(eventType string, message proto.Message) {
+  registry.mu.Lock()
+  defer registry.mu.Unlock()
+  registry.types[eventType] = message.ProtoReflect().Type()
+}
+
+// Lookup returns the message type registered for an event type.
+func (registry *Registry) Lookup(eventType string) (protoreflect.MessageType, bool) {
+  registry.mu.RLock()
+  defer registry.mu.RUnlock()
+  messageType, ok := registry.types[eventType]
+  return messageType, ok
+}
+
+// Entries lists the registered types sorted by event type.
+func (registry *Registry) Entries() []RegistryEntry {
+  registry.mu.RLock()
+  defer registry.mu.RUnlock()
+
+  entries := make([]RegistryEntry, 0, len(registry.types))
+  for eventType, messageType := range registry.types {
+    message := messageType.New().Interface()
+    entries = append(entries, RegistryEntry{
+      Type:       eventType,
+      Message:    string(messageType.Descriptor().FullName()),
+      DataSchema: typeUrl(message),
+    })
+  }
+  sort.Slice(entries, func(i, j int) bool { return entries[i].Type < entries[j].Type })
+  return entries
+}
+
+// ParseUnknownType validates the policy applied to event types missing from
+// the registry.
+func ParseUnknownType(policy string) (string, error) {
+  switch strings.ToLower(policy) {
+  case "", UnknownTypePassThrough:
+    return UnknownTypePassThrough, nil
+  case UnknownTypeReject, UnknownTypeWarn:
+    return strings.ToLower(policy), nil
+  default:
+    return "", Error(ErrInvalidFormat, fmt.Sprintf("unknown type policy %q, expected reject, pass-through or warn", policy))
+  }
+}
+
+// MessageFromContext returns the typed data decoded for the event being
+// handled, when its type is registered.
+func MessageFromContext(ctx context.Context) (proto.Message, bool) {
+  message, ok := ctx.Value(messageKey{}).(proto.Message)
+  return message, ok
+}
+
+// SetUnknownType selects the policy for event types missing from the
+// registry, for both sending and receiving.
+func (manager *CloudEventManager) SetUnknownType(policy string) error {
+  policy, err := ParseUnknownType(policy)
+  if err != nil {
+    return err
+  }
+  manager.unknownType = policy
+  return nil
+}
+
+// ExpectedMessage names the message registered for an event type, or returns
+// an empty name for unknown types the policy lets through.
+func (manager *CloudEventManager) ExpectedMessage(eventType string) (string, error) {
+  if messageType, ok := Types.Lookup(eventType); ok {
+    return string(messageType.Descriptor().FullName()), nil
+  }
+
+  switch manager.unknownType {
+  case UnknownTypeReject:
+    return "", Error(ErrInvalidFormat, fmt.Sprintf("event type %q is not registered", eventType))
+  case UnknownTypeWarn:
+    log.Printf("Event type %q is not registered, passing its data through", eventType)
+  }
+  return "", nil
+}
+
+// typed decodes the data of registered event types into their message, made
+// available to callbacks through MessageFromContext.
+func (manager *CloudEventManager) typed(ctx context.Context, event cloudevents.Event) (context.Context, error) {
+  name, err := manager.ExpectedMessage(event.Type())
+  if err != nil || name == "" {
+    return ctx, err
+  }
+
+  message, err := NewMessage(name)
+  if err != nil {
+    return ctx, err
+  }
+
+  if strings.HasPrefix(event.DataContentType(), protobuf.ContentTypeProtobuf) {
+    err = event.DataAs(message)
+  } else {
+    err = protojson.Unmarshal(event.Data(), message)
+  }
+  if err != nil {
+    return ctx, Error(ErrInvalidFormat, fmt.Sprintf("data of %s is not a valid %s: %v", event.Type(), name, err))
+  }
+  return context.WithValue(ctx, messageKey{}, message), nil
+}
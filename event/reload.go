@@ -0,0 +1,216 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay coalesces the writes of a certificate rotation, which usually
+// replaces the certificate, key and CA one after the other.
+const reloadDelay = 250 * time.Millisecond
+
+// certReloader keeps the key pair and trusted roots current. Handshakes read
+// them through the tls.Config callbacks, so a rotation only affects new
+// connections and established ones are never dropped.
+type certReloader struct {
+  name string
+  certFile string
+  keyFile string
+  caFile string
+  optional bool
+
+  mu sync.RWMutex
+  cert *tls.Certificate
+  roots *x509.CertPool
+}
+
+func newCertReloader(name string, certFile string, keyFile string, caFile string, optional bool) (*certReloader, error) {
+  reloader := &certReloader{name: name, certFile: certFile, keyFile: keyFile, caFile: caFile, optional: optional}
+  if err := reloader.load(); err != nil {
+    return nil, err
+  }
+  return reloader, nil
+}
+
+// reload reads the files again, keeping the previous material on failure.
+func (reloader *certReloader) reload() {
+  if err := reloader.load(); err != nil {
+    log.Printf("Failed to reload %s certificate, keeping the previous one: %v", reloader.name, err)
+    return
+  }
+  reloader.describe("Reloaded")
+}
+
+func (reloader *certReloader) load() error {
+  roots, err := readPool(reloader.caFile)
+  if err != nil {
+    return err
+  }
+
+  var cert *tls.Certificate
+  pair, err := tls.LoadX509KeyPair(reloader.certFile, reloader.keyFile)
+  switch {
+  case err == nil:
+    cert = &pair
+  case !reloader.optional:
+    return Error(ErrTlsConfig, fmt.Sprintf("Failed to load TLS certificates: %v", err))
+  }
+
+  reloader.mu.Lock()
+  reloader.cert = cert
+  reloader.roots = roots
+  reloader.mu.Unlock()
+  return nil
+}
+
+// describe logs the serial and expiry of the current certificate.
+func (reloader *certReloader) describe(action string) {
+  cert := reloader.certificate()
+  if cert == nil {
+    return
+  }
+  if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil {
+    log.Printf("%s %s certificate %s, serial %X, expires %s", action, reloader.name, leaf.Subject, leaf.SerialNumber, leaf.NotAfter.Format(time.RFC3339))
+  }
+}
+
+func (reloader *certReloader) certificate() *tls.Certificate {
+  reloader.mu.RLock()
+  defer reloader.mu.RUnlock()
+  return reloader.cert
+}
+
+func (reloader *certReloader) pool() *x509.CertPool {
+  reloader.mu.RLock()
+  defer reloader.mu.RUnlock()
+  return reloader.roots
+}
+
+// GetCertificate serves the current server certificate.
+func (reloader *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+  cert := reloader.certificate()
+  if cert == nil {
+    return nil, Error(ErrTlsConfig, "no server certificate loaded")
+  }
+  return cert, nil
+}
+
+// GetClientCertificate presents the current client certificate, or none when
+// only server authentication is configured.
+func (reloader *certReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+  if cert := reloader.certificate(); cert != nil {
+    return cert, nil
+  }
+  return &tls.Certificate{}, nil
+}
+
+// verifyServer checks the server chain against the current roots. It stands
+// in for the built-in verification, which cannot pick up new roots. IP
+// targets are not sent as SNI, so the chain must then be valid for one of
+// the hosts the transport dials.
+func (reloader *certReloader) verifyServer(hosts []string) func(tls.ConnectionState) error {
+  return func(state tls.ConnectionState) error {
+    names := hosts
+    if state.ServerName != "" {
+      names = []string{state.ServerName}
+    }
+    if len(names) == 0 || len(state.PeerCertificates) == 0 {
+      return Error(ErrTlsConfig, "cannot verify the server certificate")
+    }
+
+    var err error
+    for _, name := range names {
+      if err = reloader.verify(name, state.PeerCertificates); err == nil {
+        return nil
+      }
+    }
+    return err
+  }
+}
+
+func (reloader *certReloader) verify(name string, chain []*x509.Certificate) error {
+  options := x509.VerifyOptions{
+    DNSName:       name,
+    Roots:         reloader.pool(),
+    Intermediates: x509.NewCertPool(),
+  }
+  for _, cert := range chain[1:] {
+    options.Intermediates.AddCert(cert)
+  }
+
+  _, err := chain[0].Verify(options)
+  return err
+}
+
+// watch reloads on SIGHUP and whenever one of the files changes, until ctx is
+// done. Directories are watched since rotations often replace files.
+func (reloader *certReloader) watch(ctx context.Context) {
+  watcher, err := fsnotify.NewWatcher()
+  if err != nil {
+    log.Printf("Certificate watcher unavailable, reload with SIGHUP: %v", err)
+  }
+
+  files := map[string]bool{}
+  for _, file := range []string{reloader.certFile, reloader.keyFile, reloader.caFile} {
+    path, err := filepath.Abs(file)
+    if err != nil || files[path] {
+      continue
+    }
+    files[path] = true
+    if watcher != nil {
+      if err := watcher.Add(filepath.Dir(path)); err != nil {
+        log.Printf("Failed to watch %s: %v", file, err)
+      }
+    }
+  }
+
+  hangup := make(chan os.Signal, 1)
+  signal.Notify(hangup, syscall.SIGHUP)
+
+  go func() {
+    defer signal.Stop(hangup)
+
+    var events chan fsnotify.Event
+    var errors chan error
+    if watcher != nil {
+      defer func() { _ = watcher.Close() }()
+      events, errors = watcher.Events, watcher.Errors
+    }
+
+    timer := time.NewTimer(reloadDelay)
+    timer.Stop()
+    defer timer.Stop()
+
+    for {
+      select {
+      case <-ctx.Done():
+        return
+      case <-hangup:
+        log.Printf("Received SIGHUP, reloading %s certificate", reloader.name)
+        timer.Reset(0)
+      case event := <-events:
+        if files[filepath.Clean(event.Name)] && !event.Has(fsnotify.Chmod) {
+          timer.Reset(reloadDelay)
+        }
+      case err := <-errors:
+        log.Printf("Certificate watcher error: %v", err)
+      case <-timer.C:
+        reloader.reload()
+      }
+    }
+  }()
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	cehttp "github.com/cloudevents/sdk-go/v2/protocol/http"
+)
+
+const (
+  StatusDelivered DeliveryStatus = iota
+  StatusNotAccepted
+  StatusUndelivered
+)
+
+type DeliveryStatus int
+
+func (status DeliveryStatus) String() string {
+  switch status {
+  case StatusDelivered:
+    return "delivered"
+  case StatusNotAccepted:
+    return "not-accepted"
+  default:
+    return "undelivered"
+  }
+}
+
+// Attempt records the outcome of a single send attempt.
+type Attempt struct {
+  Number     int           `json:"number"`
+  StatusCode int           `json:"statusCode,omitempty"`
+  Latency    time.Duration `json:"latency"`
+  Err        error         `json:"-"`
+}
+
+// DeliveryReport summarizes every attempt made by Send and its final outcome.
+type DeliveryReport struct {
+  Status     DeliveryStatus `json:"status"`
+  StatusCode int            `json:"statusCode,omitempty"`
+  Attempts   []Attempt      `json:"attempts"`
+}
+
+func (report *DeliveryReport) Delivered() bool { return report.Status == StatusDelivered }
+
+// ExitCode maps the final delivery status to a process exit code.
+func (report *DeliveryReport) ExitCode() int {
+  switch report.Status {
+  case StatusDelivered:
+    return 0
+  case StatusNotAccepted:
+    return int(ErrNotAccepted)
+  default:
+    return int(ErrSendFailed)
+  }
+}
+
+func (report *DeliveryReport) record(number int, result error, latency time.Duration) {
+  attempt := Attempt{Number: number, Latency: latency, Err: result}
+  if cloudevents.IsACK(result) {
+    attempt.Err = nil
+  }
+
+  var httpResult *cehttp.Result
+  if cloudevents.ResultAs(result, &httpResult) {
+    attempt.StatusCode = httpResult.StatusCode
+  }
+
+  // Transport failures surface as NACK receipts; only a response from the
+  // recipient counts as a rejection.
+  var netErr net.Error
+  switch {
+  case cloudevents.IsACK(result):
+    report.Status = StatusDelivered
+  case cloudevents.IsNACK(result) && !errors.As(result, &netErr):
+    report.Status = StatusNotAccepted
+  default:
+    report.Status = StatusUndelivered
+  }
+
+  report.StatusCode = attempt.StatusCode
+  report.Attempts = append(report.Attempts, attempt)
+}
@@ -3,10 +3,78 @@
 
 package cloudevent
 
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+  JitterNone Jitter = iota
+  JitterFull
+  JitterDecorrelated
+)
+
+// DefaultRetryableStatus lists the HTTP status codes worth retrying; any other
+// rejection is treated as permanent.
+var DefaultRetryableStatus = []int{
+  http.StatusRequestTimeout,
+  http.StatusTooEarly,
+  http.StatusTooManyRequests,
+  http.StatusInternalServerError,
+  http.StatusBadGateway,
+  http.StatusServiceUnavailable,
+  http.StatusGatewayTimeout,
+}
+
+type Jitter int
+
+// Backoff computes the delay before the next attempt. Attempt starts at 1 and
+// previous is the delay returned for the prior attempt (zero on the first).
+type Backoff interface {
+  Next(attempt int, previous time.Duration) time.Duration
+}
+
+type ConstantBackoff struct {
+  Delay time.Duration
+}
+
+type LinearBackoff struct {
+  Delay time.Duration
+  Max   time.Duration
+}
+
+type ExponentialBackoff struct {
+  Base   time.Duration
+  Max    time.Duration
+  Jitter Jitter
+}
+
 type Retry struct {
   Enable bool
   Attempts int
   Timeout int
+  AttemptTimeout time.Duration
+  Backoff Backoff
+  MaxElapsed time.Duration
+  RetryableStatus []int
+}
+
+// RetryConfig is the retry policy as set by a context or CE_RETRY_* variables.
+type RetryConfig struct {
+  Enable bool `envconfig:"CE_RETRY" default:"false" yaml:"enable,omitempty"`
+  Attempts int `envconfig:"CE_RETRY_ATTEMPTS" default:"3" yaml:"attempts,omitempty"`
+  Backoff string `envconfig:"CE_RETRY_BACKOFF" default:"constant" yaml:"backoff,omitempty"`
+  Delay time.Duration `envconfig:"CE_RETRY_DELAY" default:"1s" yaml:"delay,omitempty"`
+  MaxDelay time.Duration `envconfig:"CE_RETRY_MAX_DELAY" default:"30s" yaml:"max-delay,omitempty"`
+  MaxElapsed time.Duration `envconfig:"CE_RETRY_MAX_ELAPSED" default:"0s" yaml:"max-elapsed,omitempty"`
+  Jitter string `envconfig:"CE_RETRY_JITTER" default:"none" yaml:"jitter,omitempty"`
 }
 
 func DefaultRetry() Retry {
@@ -14,5 +82,167 @@ func DefaultRetry() Retry {
     Enable:   false,
     Attempts: 3,
     Timeout:  1000,
+    RetryableStatus: DefaultRetryableStatus,
+  }
+}
+
+// MARK: - Policy
+
+// Policy builds the retry policy described by the configuration.
+func (config RetryConfig) Policy() (Retry, error) {
+  strategy, err := NewBackoff(config.Backoff, config.Delay, config.MaxDelay, config.Jitter)
+  if err != nil {
+    return Retry{}, err
+  }
+
+  policy := DefaultRetry()
+  policy.Enable = config.Enable
+  policy.Attempts = config.Attempts
+  policy.Timeout = int(config.Delay.Milliseconds())
+  policy.Backoff = strategy
+  policy.MaxElapsed = config.MaxElapsed
+
+  return policy, nil
+}
+
+func (retry Retry) delay(attempt int, previous time.Duration) time.Duration {
+  if retry.Backoff == nil {
+    return time.Duration(retry.Timeout) * time.Millisecond
+  }
+  return retry.Backoff.Next(attempt, previous)
+}
+
+func (retry Retry) retryable(status int) bool {
+  // Non-HTTP rejections carry no status code and are always retried
+  if status == 0 || retry.RetryableStatus == nil {
+    return true
+  }
+  return slices.Contains(retry.RetryableStatus, status)
+}
+
+func (backoff ConstantBackoff) Next(attempt int, previous time.Duration) time.Duration {
+  return backoff.Delay
+}
+
+func (backoff LinearBackoff) Next(attempt int, previous time.Duration) time.Duration {
+  return capped(backoff.Delay*time.Duration(attempt), backoff.Max)
+}
+
+func (backoff ExponentialBackoff) Next(attempt int, previous time.Duration) time.Duration {
+  ceiling := capped(time.Duration(float64(backoff.Base)*math.Pow(2, float64(attempt-1))), backoff.Max)
+
+  switch backoff.Jitter {
+  case JitterFull:
+    return randomBetween(0, ceiling)
+  case JitterDecorrelated:
+    if previous < backoff.Base {
+      previous = backoff.Base
+    }
+    return capped(randomBetween(backoff.Base, previous*3), backoff.Max)
+  default:
+    return ceiling
+  }
+}
+
+func NewBackoff(strategy string, base time.Duration, max time.Duration, jitter string) (Backoff, error) {
+  mode, err := ParseJitter(jitter)
+  if err != nil {
+    return nil, err
+  }
+
+  switch strings.ToLower(strategy) {
+  case "", "constant":
+    return ConstantBackoff{Delay: base}, nil
+  case "linear":
+    return LinearBackoff{Delay: base, Max: max}, nil
+  case "exponential":
+    return ExponentialBackoff{Base: base, Max: max, Jitter: mode}, nil
+  default:
+    return nil, Error(ErrInvalidFormat, fmt.Sprintf("unknown backoff strategy %q", strategy))
+  }
+}
+
+func ParseJitter(jitter string) (Jitter, error) {
+  switch strings.ToLower(jitter) {
+  case "", "none":
+    return JitterNone, nil
+  case "full":
+    return JitterFull, nil
+  case "decorrelated":
+    return JitterDecorrelated, nil
+  default:
+    return JitterNone, Error(ErrInvalidFormat, fmt.Sprintf("unknown jitter mode %q", jitter))
+  }
+}
+
+// sleep waits for the delay unless the context is done first.
+func sleep(ctx context.Context, delay time.Duration) error {
+  timer := time.NewTimer(delay)
+  defer timer.Stop()
+
+  select {
+  case <-ctx.Done():
+    return ctx.Err()
+  case <-timer.C:
+    return nil
+  }
+}
+
+func capped(delay time.Duration, max time.Duration) time.Duration {
+  if max > 0 && (delay > max || delay < 0) {
+    return max
+  }
+  return delay
+}
+
+func randomBetween(low time.Duration, high time.Duration) time.Duration {
+  if high <= low {
+    return low
+  }
+  return low + rand.N(high-low)
+}
+
+// MARK: - Retry-After
+
+type retryAfterKey struct{}
+
+// retryAfter holds the server requested delay observed on the last response.
+type retryAfter struct {
+  delay time.Duration
+}
+
+func withRetryAfter(ctx context.Context) (context.Context, *retryAfter) {
+  hint := &retryAfter{}
+  return context.WithValue(ctx, retryAfterKey{}, hint), hint
+}
+
+// retryAfterTransport records Retry-After response headers into the request
+// context so Send can honor them between attempts.
+type retryAfterTransport struct {
+  base http.RoundTripper
+}
+
+func (transport retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+  res, err := transport.base.RoundTrip(req)
+  if err != nil {
+    return res, err
+  }
+
+  if hint, ok := req.Context().Value(retryAfterKey{}).(*retryAfter); ok {
+    hint.delay = parseRetryAfter(res.Header.Get("Retry-After"))
+  }
+  return res, nil
+}
+
+func parseRetryAfter(value string) time.Duration {
+  if value == "" {
+    return 0
+  }
+  if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+    return time.Duration(seconds) * time.Second
+  }
+  if at, err := http.ParseTime(value); err == nil {
+    return time.Until(at)
   }
+  return 0
 }
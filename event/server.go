@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Serve runs the server on listener until it fails or ctx is done. On
+// cancellation it stops accepting connections and waits up to grace for
+// in-flight handlers to finish.
+func Serve(ctx context.Context, server *http.Server, listener net.Listener, grace time.Duration) error {
+  errs := make(chan error, 1)
+
+  go func() {
+    if server.TLSConfig != nil {
+      errs <- server.ServeTLS(listener, "", "")
+    } else {
+      errs <- server.Serve(listener)
+    }
+  }()
+
+  select {
+  case err := <-errs:
+    if err != nil && !errors.Is(err, http.ErrServerClosed) {
+      return Error(ErrReceiveFailed, fmt.Sprintf("Server failed: %v", err))
+    }
+    return nil
+  case <-ctx.Done():
+  }
+
+  log.Printf("Shutting down, draining in-flight CloudEvents for up to %s...", grace)
+
+  shutdown, cancel := context.WithTimeout(context.Background(), grace)
+  defer cancel()
+
+  if err := server.Shutdown(shutdown); err != nil {
+    _ = server.Close()
+    return Error(ErrReceiveFailed, fmt.Sprintf("Graceful shutdown failed: %v", err))
+  }
+
+  log.Printf("Server stopped")
+  return nil
+}
+
+// ServeGRPC runs the gRPC server until it fails or ctx is done, then drains
+// in-flight calls for up to grace before closing remaining streams.
+func ServeGRPC(ctx context.Context, server *grpc.Server, listener net.Listener, grace time.Duration) error {
+  errs := make(chan error, 1)
+
+  go func() {
+    errs <- server.Serve(listener)
+  }()
+
+  select {
+  case err := <-errs:
+    if err != nil {
+      return Error(ErrReceiveFailed, fmt.Sprintf("Server failed: %v", err))
+    }
+    return nil
+  case <-ctx.Done():
+  }
+
+  log.Printf("Shutting down, draining in-flight CloudEvents for up to %s...", grace)
+
+  stopped := make(chan struct{})
+  go func() {
+    server.GracefulStop()
+    close(stopped)
+  }()
+
+  select {
+  case <-stopped:
+  case <-time.After(grace):
+    server.Stop()
+    return Error(ErrReceiveFailed, "Graceful shutdown timed out")
+  }
+
+  log.Printf("Server stopped")
+  return nil
+}
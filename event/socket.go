@@ -0,0 +1,73 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// socketMode limits a Unix domain socket to its owner and group, which takes
+// the place of TLS for local peers.
+const socketMode = 0o660
+
+// IsSocket reports whether events are exchanged over a Unix domain socket.
+func (config CloudEventConfig) IsSocket() bool {
+  return config.Socket != ""
+}
+
+// Listener opens the Unix domain socket when one is configured, otherwise the
+// TCP address and port.
+func (config CloudEventConfig) Listener() (net.Listener, error) {
+  if !config.IsSocket() {
+    listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", config.Address, config.Port))
+    if err != nil {
+      return nil, Wrap(ErrReceiveFailed, err)
+    }
+    return listener, nil
+  }
+
+  // Remove a socket left behind by a previous run that did not shut down,
+  // but never take the path over from a listener that is still running
+  if info, err := os.Stat(config.Socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+    conn, err := net.DialTimeout("unix", config.Socket, time.Second)
+    if err == nil {
+      _ = conn.Close()
+      return nil, Error(ErrReceiveFailed, fmt.Sprintf("socket %s is in use", config.Socket))
+    }
+    if !errors.Is(err, syscall.ECONNREFUSED) {
+      return nil, Wrap(ErrReceiveFailed, err, fmt.Sprintf("socket %s is in use: %v", config.Socket, err))
+    }
+    _ = os.Remove(config.Socket)
+  }
+
+  listener, err := net.Listen("unix", config.Socket)
+  if err != nil {
+    return nil, Wrap(ErrReceiveFailed, err)
+  }
+  if err := os.Chmod(config.Socket, socketMode); err != nil {
+    _ = listener.Close()
+    return nil, Wrap(ErrReceiveFailed, err)
+  }
+  return listener, nil
+}
+
+// Endpoint describes where events are exchanged, for logging.
+func (config CloudEventConfig) Endpoint() string {
+  if config.IsSocket() {
+    return "unix://" + config.Socket
+  }
+  return config.Url().String()
+}
+
+// dialSocket connects to the Unix domain socket whatever address is requested.
+func (config CloudEventConfig) dialSocket(ctx context.Context, network string, address string) (net.Conn, error) {
+  var dialer net.Dialer
+  return dialer.DialContext(ctx, "unix", config.Socket)
+}
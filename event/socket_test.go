@@ -0,0 +1,44 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListenerKeepsLiveSocketsAndReplacesStaleOnes(t *testing.T) {
+  config := CloudEventConfig{Socket: filepath.Join(t.TempDir(), "cecli.sock")}
+
+  running, err := config.Listener()
+  if err != nil {
+    t.Fatal(err)
+  }
+
+  var failure *CloudEventError
+  if _, err := config.Listener(); !errors.As(err, &failure) || failure.Code != ErrReceiveFailed {
+    t.Fatalf("second Listener = %v, want ErrReceiveFailed while the socket is in use", err)
+  }
+  if _, err := net.Dial("unix", config.Socket); err != nil {
+    t.Fatalf("the running listener lost its socket: %v", err)
+  }
+
+  // Leave the socket file behind, as a listener that did not shut down does
+  running.(*net.UnixListener).SetUnlinkOnClose(false)
+  if err := running.Close(); err != nil {
+    t.Fatal(err)
+  }
+  if _, err := os.Stat(config.Socket); err != nil {
+    t.Fatal(err)
+  }
+
+  replaced, err := config.Listener()
+  if err != nil {
+    t.Fatalf("stale socket was not replaced: %v", err)
+  }
+  _ = replaced.Close()
+}
@@ -0,0 +1,217 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+  ClientAuthNone = "none"
+  ClientAuthVerifyIfGiven = "verify-if-given"
+  ClientAuthRequire = "require"
+)
+
+// ParseClientAuth maps a client authentication policy to its TLS setting.
+func ParseClientAuth(policy string) (tls.ClientAuthType, error) {
+  switch strings.ToLower(policy) {
+  case "", ClientAuthNone:
+    return tls.NoClientCert, nil
+  case ClientAuthVerifyIfGiven:
+    return tls.VerifyClientCertIfGiven, nil
+  case ClientAuthRequire:
+    return tls.RequireAndVerifyClientCert, nil
+  default:
+    return tls.NoClientCert, Error(ErrTlsConfig, fmt.Sprintf("unknown client auth %q", policy))
+  }
+}
+
+// loadTLS populates Config with the client certificate and trusted roots,
+// both reloaded when their files change until ctx is done. Without a dedicated CA bundle the
+// certificate bundle doubles as the roots, and the client certificate falls
+// back to the shared certificate. hosts are the servers the transport dials.
+func (config *CloudEventConfig) loadTLS(ctx context.Context, hosts ...string) error {
+  // A dedicated CA is enough for server-only TLS unless a client
+  // certificate was asked for explicitly
+  optional := config.CA != "" && config.ClientCertificate == ""
+
+  certFile, keyFile := config.clientKeyPair()
+  reloader, err := newCertReloader("client", certFile, keyFile, config.caFile(), optional)
+  if err != nil {
+    return err
+  }
+  reloader.watch(ctx)
+
+  config.Config = &tls.Config{
+    RootCAs:              reloader.pool(),
+    GetClientCertificate: reloader.GetClientCertificate,
+    InsecureSkipVerify:   config.SkipVerify,
+  }
+  if !config.SkipVerify {
+    // Verify against the reloaded roots instead of the fixed RootCAs
+    config.Config.InsecureSkipVerify = true
+    config.Config.VerifyConnection = reloader.verifyServer(hosts)
+  }
+  return nil
+}
+
+// ServerTLS builds the listener TLS configuration, verifying client
+// certificates against the CA bundle according to ClientAuth. The key pair
+// and CA are reloaded until ctx is done.
+func (config CloudEventConfig) ServerTLS(ctx context.Context) (*tls.Config, error) {
+  auth, err := ParseClientAuth(config.ClientAuth)
+  if err != nil {
+    return nil, err
+  }
+
+  caFile := ""
+  if auth != tls.NoClientCert {
+    caFile = config.caFile()
+  }
+
+  certFile, keyFile := config.serverKeyPair()
+  reloader, err := newCertReloader("server", certFile, keyFile, caFile, false)
+  if err != nil {
+    return nil, err
+  }
+  reloader.describe("Loaded")
+  reloader.watch(ctx)
+
+  server := &tls.Config{GetCertificate: reloader.GetCertificate, ClientAuth: auth, ClientCAs: reloader.pool()}
+  if auth != tls.NoClientCert {
+    server.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+      current := server.Clone()
+      current.GetConfigForClient = nil
+      current.ClientCAs = reloader.pool()
+      return current, nil
+    }
+  }
+  return server, nil
+}
+
+// caFile is the CA bundle, or the certificate bundle when no CA is configured.
+func (config CloudEventConfig) caFile() string {
+  if config.CA != "" {
+    return config.CA
+  }
+  return config.Certificate
+}
+
+// readPool reads the certificates of a PEM bundle, an empty file name
+// meaning no roots are needed.
+func readPool(file string) (*x509.CertPool, error) {
+  if file == "" {
+    return nil, nil
+  }
+
+  ca, err := os.ReadFile(file)
+  if err != nil {
+    return nil, Wrap(ErrTlsConfig, err)
+  }
+
+  pool := x509.NewCertPool()
+  if !pool.AppendCertsFromPEM(ca) {
+    return nil, Error(ErrTlsConfig, fmt.Sprintf("no certificates found in %s", file))
+  }
+  return pool, nil
+}
+
+func (config CloudEventConfig) clientKeyPair() (string, string) {
+  if config.ClientCertificate != "" {
+    return config.ClientCertificate, config.ClientKey
+  }
+  return config.Certificate, config.CertificateKey
+}
+
+// hostOf strips the port from a host:port address.
+func hostOf(address string) string {
+  if host, _, err := net.SplitHostPort(address); err == nil {
+    return host
+  }
+  return address
+}
+
+// urlHosts lists the hosts of a comma separated list of URLs.
+func urlHosts(urls string) []string {
+  var hosts []string
+  for _, target := range strings.Split(urls, ",") {
+    if parsed, err := url.Parse(strings.TrimSpace(target)); err == nil && parsed.Hostname() != "" {
+      hosts = append(hosts, parsed.Hostname())
+    }
+  }
+  return hosts
+}
+
+func (config CloudEventConfig) serverKeyPair() (string, string) {
+  if config.ServerCertificate != "" {
+    return config.ServerCertificate, config.ServerKey
+  }
+  return config.Certificate, config.CertificateKey
+}
+
+// MARK: - Peer Identity
+
+type peerKey struct{}
+
+// PeerIdentity describes the verified certificate a sender presented.
+type PeerIdentity struct {
+  Subject string
+  DNSNames []string
+  EmailAddresses []string
+  IPAddresses []string
+  URIs []string
+  Certificate *x509.Certificate
+}
+
+// PeerFromContext returns the verified identity of the sender of the event
+// being handled, if it authenticated with a client certificate.
+func PeerFromContext(ctx context.Context) (*PeerIdentity, bool) {
+  identity, ok := ctx.Value(peerKey{}).(*PeerIdentity)
+  return identity, ok
+}
+
+// SANs lists every subject alternative name of the peer.
+func (identity *PeerIdentity) SANs() []string {
+  names := append([]string{}, identity.DNSNames...)
+  names = append(names, identity.EmailAddresses...)
+  names = append(names, identity.IPAddresses...)
+  return append(names, identity.URIs...)
+}
+
+// withPeer stores the identity from a verified TLS connection in ctx.
+func withPeer(ctx context.Context, state *tls.ConnectionState) context.Context {
+  if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+    return ctx
+  }
+
+  cert := state.VerifiedChains[0][0]
+  identity := &PeerIdentity{
+    Subject: cert.Subject.String(),
+    DNSNames: cert.DNSNames,
+    EmailAddresses: cert.EmailAddresses,
+    Certificate: cert,
+  }
+  for _, ip := range cert.IPAddresses {
+    identity.IPAddresses = append(identity.IPAddresses, ip.String())
+  }
+  for _, uri := range cert.URIs {
+    identity.URIs = append(identity.URIs, uri.String())
+  }
+  return context.WithValue(ctx, peerKey{}, identity)
+}
+
+// identify makes the peer identity of TLS requests available to callbacks.
+func identify(next http.Handler) http.Handler {
+  return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+    next.ServeHTTP(w, req.WithContext(withPeer(req.Context(), req.TLS)))
+  })
+}
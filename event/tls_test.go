@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"crypto/tls"
+	"path/filepath"
+	"testing"
+)
+
+// serveTLS issues a server certificate for hosts from a fresh CA, written to
+// a temp dir as ca.pem, and accepts TLS handshakes on 127.0.0.1.
+func serveTLS(t *testing.T, hosts ...string) (string, string) {
+  t.Helper()
+
+  dir := t.TempDir()
+  key := KeyRequest{Algo: "ecdsa", Size: 256}
+  profile := SigningProfile{Expiry: "1h", Usages: []string{"cert sign"}}
+  profile.CAConstraint.IsCA = true
+  ca, err := InitCA(CertRequest{CN: "test ca", Key: key}, profile)
+  if err != nil {
+    t.Fatal(err)
+  }
+  server, err := Sign(CertRequest{CN: "server", Key: key, Hosts: hosts}, SigningProfile{Expiry: "1h", Usages: []string{"server auth"}}, ca)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if err := ca.Write(dir, "ca"); err != nil {
+    t.Fatal(err)
+  }
+
+  listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+    Certificates: []tls.Certificate{{Certificate: [][]byte{server.Certificate.Raw}, PrivateKey: server.Key}},
+  })
+  if err != nil {
+    t.Fatal(err)
+  }
+  t.Cleanup(func() { _ = listener.Close() })
+  go func() {
+    for {
+      conn, err := listener.Accept()
+      if err != nil {
+        return
+      }
+      _ = conn.(*tls.Conn).Handshake()
+      _ = conn.Close()
+    }
+  }()
+  return listener.Addr().String(), filepath.Join(dir, "ca.pem")
+}
+
+func TestClientTLSVerifiesTheDialedHost(t *testing.T) {
+  tests := []struct {
+    name  string
+    sans  []string
+    valid bool
+  }{
+    {"issued for the dialed IP", []string{"127.0.0.1"}, true},
+    {"issued for the configured address only", []string{"events.example.com"}, false},
+  }
+
+  for _, test := range tests {
+    t.Run(test.name, func(t *testing.T) {
+      address, caFile := serveTLS(t, test.sans...)
+
+      // The broker is dialed by IP, which differs from the configured address
+      config := &CloudEventConfig{Address: "events.example.com", CA: caFile}
+      if err := config.loadTLS(t.Context(), hostOf(address)); err != nil {
+        t.Fatal(err)
+      }
+
+      conn, err := tls.Dial("tcp", address, config.Config)
+      if err == nil {
+        _ = conn.Close()
+      }
+      if (err == nil) != test.valid {
+        t.Errorf("handshake error = %v, want valid %v", err, test.valid)
+      }
+    })
+  }
+}
+
+func TestHostsOfDialedURLs(t *testing.T) {
+  if host := hostOf("10.0.0.5:9092"); host != "10.0.0.5" {
+    t.Errorf("hostOf = %q", host)
+  }
+  hosts := urlHosts("nats://a.example.com:4222, tls://10.0.0.6:4222")
+  if len(hosts) != 2 || hosts[0] != "a.example.com" || hosts[1] != "10.0.0.6" {
+    t.Errorf("urlHosts = %v", hosts)
+  }
+}
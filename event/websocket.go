@@ -0,0 +1,382 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	protobuf "github.com/cloudevents/sdk-go/binding/format/protobuf/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/cloudevents/sdk-go/v2/protocol"
+	"github.com/gorilla/websocket"
+)
+
+// Subprotocols defined by the CloudEvents WebSocket binding.
+const (
+  SubprotocolJSON = "cloudevents.json"
+  SubprotocolProtobuf = "cloudevents.proto"
+)
+
+// publishQuery marks sockets that only publish, so they are not streamed the
+// events the listener receives.
+const publishQuery = "publish"
+
+// WebSocketConfig lists the origins allowed to open a socket. When empty only
+// same-origin requests are accepted; "*" accepts any origin.
+type WebSocketConfig struct {
+  Origins []string `envconfig:"CE_WS_ORIGINS"`
+}
+
+// subprotocol names the WebSocket subprotocol for an event format.
+func subprotocol(f format.Format) string {
+  if IsProtobuf(f) {
+    return SubprotocolProtobuf
+  }
+  return SubprotocolJSON
+}
+
+// subprotocolFormat is the inverse of subprotocol, defaulting to JSON when the
+// peer did not negotiate one.
+func subprotocolFormat(name string) format.Format {
+  if name == SubprotocolProtobuf {
+    return protobuf.Protobuf
+  }
+  return format.JSON
+}
+
+// messageType uses binary frames for protobuf and text frames otherwise.
+func messageType(f format.Format) int {
+  if IsProtobuf(f) {
+    return websocket.BinaryMessage
+  }
+  return websocket.TextMessage
+}
+
+// MARK: - Client
+
+// WebSocketClient is a cloudevents.Client that publishes events over a single
+// persistent socket, dialed on first use.
+type WebSocketClient struct {
+  target string
+  dialer *websocket.Dialer
+  format format.Format
+  mutex sync.Mutex
+  conn *websocket.Conn
+}
+
+func NewWebSocketClient(target string, dialer *websocket.Dialer, f format.Format) *WebSocketClient {
+  if f == nil {
+    f = format.JSON
+  }
+  dialer.Subprotocols = []string{subprotocol(f)}
+  return &WebSocketClient{target: target, dialer: dialer, format: f}
+}
+
+func (client *WebSocketClient) Send(ctx context.Context, event cloudevents.Event) protocol.Result {
+  return client.SendBatch(ctx, []cloudevents.Event{event})
+}
+
+// SendBatch writes each event as its own message on the shared socket.
+func (client *WebSocketClient) SendBatch(ctx context.Context, events []cloudevents.Event) protocol.Result {
+  client.mutex.Lock()
+  defer client.mutex.Unlock()
+
+  conn, err := client.connect(ctx, true)
+  if err != nil {
+    return err
+  }
+
+  if deadline, ok := ctx.Deadline(); ok {
+    _ = conn.SetWriteDeadline(deadline)
+    defer func() { _ = conn.SetWriteDeadline(time.Time{}) }()
+  }
+
+  for _, event := range events {
+    payload, err := client.format.Marshal(&event)
+    if err != nil {
+      return Wrap(ErrInvalidFormat, err)
+    }
+    if err := conn.WriteMessage(messageType(client.format), payload); err != nil {
+      // Drop the broken socket so the next attempt dials again
+      _ = conn.Close()
+      client.conn = nil
+      return err
+    }
+  }
+  return protocol.ResultACK
+}
+
+// Close ends the socket with a normal closure, if one is open.
+func (client *WebSocketClient) Close(ctx context.Context) error {
+  client.mutex.Lock()
+  defer client.mutex.Unlock()
+
+  if client.conn == nil {
+    return nil
+  }
+  closure := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+  _ = client.conn.WriteControl(websocket.CloseMessage, closure, time.Now().Add(time.Second))
+  err := client.conn.Close()
+  client.conn = nil
+  return err
+}
+
+func (client *WebSocketClient) Request(ctx context.Context, event cloudevents.Event) (*cloudevents.Event, protocol.Result) {
+  return nil, client.Send(ctx, event)
+}
+
+// StartReceiver invokes fn for every event the server streams until ctx is done.
+func (client *WebSocketClient) StartReceiver(ctx context.Context, fn interface{}) error {
+  handler, ok := fn.(func(context.Context, cloudevents.Event) error)
+  if !ok {
+    return Error(ErrReceiveFailed, fmt.Sprintf("unsupported receiver %T", fn))
+  }
+
+  client.mutex.Lock()
+  conn, err := client.connect(ctx, false)
+  client.mutex.Unlock()
+  if err != nil {
+    return Wrap(ErrReceiveFailed, err)
+  }
+
+  // Unblock the read when ctx is done, without outliving a connection the
+  // peer already closed
+  done := make(chan struct{})
+  defer close(done)
+  go func() {
+    select {
+    case <-ctx.Done():
+      _ = conn.Close()
+    case <-done:
+    }
+  }()
+
+  for {
+    _, payload, err := conn.ReadMessage()
+    if ctx.Err() != nil {
+      return nil
+    }
+    if err != nil {
+      return Wrap(ErrReceiveFailed, err)
+    }
+
+    event := cloudevents.NewEvent()
+    if err := client.format.Unmarshal(payload, &event); err != nil {
+      log.Printf("Dropping malformed WebSocket message: %v", err)
+      continue
+    }
+    if err := handler(ctx, event); err != nil {
+      log.Printf("Callback failed for CloudEvent %s: %v", event.ID(), err)
+    }
+  }
+}
+
+// connect dials the socket once. Publishing sockets say so, since the
+// server would otherwise stream every event back to them.
+func (client *WebSocketClient) connect(ctx context.Context, publish bool) (*websocket.Conn, error) {
+  if client.conn != nil {
+    return client.conn, nil
+  }
+
+  target := client.target
+  if publish {
+    parsed, err := url.Parse(target)
+    if err != nil {
+      return nil, err
+    }
+    query := parsed.Query()
+    query.Set(publishQuery, "true")
+    parsed.RawQuery = query.Encode()
+    target = parsed.String()
+  }
+
+  conn, _, err := client.dialer.DialContext(ctx, target, nil)
+  if err != nil {
+    return nil, err
+  }
+  client.conn = conn
+  return conn, nil
+}
+
+func (config CloudEventConfig) websocketClient(ctx context.Context) (cloudevents.Client, error) {
+  eventFormat, err := ParseFormat(config.Format)
+  if err != nil {
+    return nil, err
+  }
+
+  dialer := &websocket.Dialer{Proxy: http.ProxyFromEnvironment}
+  if config.IsSocket() {
+    dialer.NetDialContext = config.dialSocket
+  } else if !config.Insecure {
+    if err := config.loadTLS(ctx, config.Url().Hostname()); err != nil {
+      return nil, err
+    }
+    dialer.TLSClientConfig = config.Config
+  }
+  return NewWebSocketClient(config.Url().String(), dialer, eventFormat), nil
+}
+
+// MARK: - Server
+
+// websocketHub tracks connected sockets and fans events out to them.
+type websocketHub struct {
+  upgrader websocket.Upgrader
+  mutex sync.Mutex
+  peers map[*websocketPeer]struct{}
+}
+
+// websocketPeer owns the writes to one socket. Only subscribers are sent
+// broadcasts.
+type websocketPeer struct {
+  conn *websocket.Conn
+  format format.Format
+  outbox chan []byte
+  subscribe bool
+}
+
+func newWebsocketHub(origins []string) *websocketHub {
+  hub := &websocketHub{peers: map[*websocketPeer]struct{}{}}
+  hub.upgrader.Subprotocols = []string{SubprotocolJSON, SubprotocolProtobuf}
+
+  if len(origins) > 0 {
+    hub.upgrader.CheckOrigin = func(req *http.Request) bool {
+      return slices.Contains(origins, "*") || slices.Contains(origins, req.Header.Get("Origin"))
+    }
+  }
+  return hub
+}
+
+// WebSocketHandler upgrades WebSocket requests into event streams and hands
+// every other request to next. Events received by the manager, over any of
+// these paths, are streamed to all connected sockets except those opened
+// with ?publish=true.
+func (manager *CloudEventManager) WebSocketHandler(config *CloudEventConfig, next http.Handler) http.Handler {
+  manager.hub = newWebsocketHub(config.WebSocket.Origins)
+
+  return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+    if !websocket.IsWebSocketUpgrade(req) {
+      next.ServeHTTP(w, req)
+      return
+    }
+
+    conn, err := manager.hub.upgrader.Upgrade(w, req, nil)
+    if err != nil {
+      return
+    }
+    publish, _ := strconv.ParseBool(req.URL.Query().Get(publishQuery))
+    manager.stream(req.Context(), conn, !publish)
+  })
+}
+
+// stream serves one socket: events written by the peer are dispatched like
+// HTTP requests while broadcasts are written back to subscribers until either
+// side closes.
+func (manager *CloudEventManager) stream(ctx context.Context, conn *websocket.Conn, subscribe bool) {
+  peer := &websocketPeer{conn: conn, format: subprotocolFormat(conn.Subprotocol()), outbox: make(chan []byte, 64), subscribe: subscribe}
+  manager.hub.join(peer)
+  log.Printf("WebSocket client %s connected using %s", conn.RemoteAddr(), subprotocol(peer.format))
+
+  go peer.write()
+  defer func() {
+    manager.hub.leave(peer)
+    log.Printf("WebSocket client %s disconnected", conn.RemoteAddr())
+  }()
+
+  for {
+    _, payload, err := conn.ReadMessage()
+    if err != nil {
+      return
+    }
+
+    event := cloudevents.NewEvent()
+    if err := peer.format.Unmarshal(payload, &event); err != nil {
+      log.Printf("Dropping malformed WebSocket message from %s: %v", conn.RemoteAddr(), err)
+      continue
+    }
+    if err := manager.dispatch(ctx, event); err != nil {
+      log.Printf("Callback failed for CloudEvent %s: %v", event.ID(), err)
+    }
+  }
+}
+
+func (peer *websocketPeer) write() {
+  for payload := range peer.outbox {
+    if err := peer.conn.WriteMessage(messageType(peer.format), payload); err != nil {
+      _ = peer.conn.Close()
+      return
+    }
+  }
+  _ = peer.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+  _ = peer.conn.Close()
+}
+
+func (hub *websocketHub) join(peer *websocketPeer) {
+  hub.mutex.Lock()
+  defer hub.mutex.Unlock()
+  hub.peers[peer] = struct{}{}
+}
+
+func (hub *websocketHub) leave(peer *websocketPeer) {
+  hub.mutex.Lock()
+  defer hub.mutex.Unlock()
+  if _, ok := hub.peers[peer]; ok {
+    delete(hub.peers, peer)
+    close(peer.outbox)
+  }
+}
+
+// broadcast queues an event for every subscriber, dropping it for any
+// subscriber that is not keeping up.
+func (hub *websocketHub) broadcast(event cloudevents.Event) {
+  hub.mutex.Lock()
+  defer hub.mutex.Unlock()
+
+  for peer := range hub.peers {
+    if !peer.subscribe {
+      continue
+    }
+    payload, err := peer.format.Marshal(&event)
+    if err != nil {
+      log.Printf("Failed to encode CloudEvent %s for %s: %v", event.ID(), peer.conn.RemoteAddr(), err)
+      continue
+    }
+
+    select {
+    case peer.outbox <- payload:
+    default:
+      log.Printf("WebSocket client %s is too slow, dropping CloudEvent %s", peer.conn.RemoteAddr(), event.ID())
+    }
+  }
+}
+
+// close disconnects every peer, used when the server shuts down since
+// hijacked connections are not closed by http.Server.Shutdown.
+func (hub *websocketHub) close() {
+  hub.mutex.Lock()
+  defer hub.mutex.Unlock()
+
+  for peer := range hub.peers {
+    delete(hub.peers, peer)
+    close(peer.outbox)
+  }
+}
+
+// websocketUrl switches an http(s) URL to ws(s).
+func websocketUrl(target *url.URL) *url.URL {
+  if target.Scheme == "https" {
+    target.Scheme = "wss"
+  } else {
+    target.Scheme = "ws"
+  }
+  return target
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: GPL-3.0
+// Copyright (c) 2025 Schubert Anselme <schubert@anselm.es>
+
+package cloudevent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/binding/format"
+	"github.com/gorilla/websocket"
+
+	api "github.com/anselmes/ce-go-template/api/v1"
+)
+
+func TestWebSocketBroadcastsOnlyToSubscribers(t *testing.T) {
+  manager := NewCloudEventManager(&api.Data{}, nil)
+  manager.SetCallback(func(ctx context.Context, event cloudevents.Event) error { return nil })
+  server := httptest.NewServer(manager.WebSocketHandler(&CloudEventConfig{}, http.NotFoundHandler()))
+  defer server.Close()
+  target := "ws" + strings.TrimPrefix(server.URL, "http")
+
+  subscriber, _, err := websocket.DefaultDialer.Dial(target, nil)
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer func() { _ = subscriber.Close() }()
+  waitFor(t, "the subscriber to join", func() bool {
+    manager.hub.mutex.Lock()
+    defer manager.hub.mutex.Unlock()
+    return len(manager.hub.peers) == 1
+  })
+
+  publisher := NewWebSocketClient(target, &websocket.Dialer{}, format.JSON)
+  sent := NewCloudEventManager(&api.Data{Message: "created"}, &CloudEventOptions{Source: "/orders", Type: "com.example.order", ID: "order-7"})
+  if result := publisher.Send(context.Background(), sent.Event); !cloudevents.IsACK(result) {
+    t.Fatalf("Send: %v", result)
+  }
+
+  _ = subscriber.SetReadDeadline(time.Now().Add(5 * time.Second))
+  _, payload, err := subscriber.ReadMessage()
+  if err != nil {
+    t.Fatalf("subscriber received nothing: %v", err)
+  }
+  received := cloudevents.NewEvent()
+  if err := format.JSON.Unmarshal(payload, &received); err != nil || received.ID() != "order-7" {
+    t.Errorf("subscriber received %s (%v), want order-7", payload, err)
+  }
+
+  // The publisher is not streamed its own event back
+  _ = publisher.conn.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+  _, echo, err := publisher.conn.ReadMessage()
+  var timeout net.Error
+  if !errors.As(err, &timeout) || !timeout.Timeout() {
+    t.Errorf("publisher received %s (%v), want nothing", echo, err)
+  }
+}
+
+func TestWebSocketReceiverReturnsWhenThePeerCloses(t *testing.T) {
+  upgrader := websocket.Upgrader{}
+  server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+    conn, err := upgrader.Upgrade(writer, request, nil)
+    if err != nil {
+      return
+    }
+    _ = conn.Close()
+  }))
+  defer server.Close()
+  before := runtime.NumGoroutine()
+
+  receiver := NewWebSocketClient("ws"+strings.TrimPrefix(server.URL, "http"), &websocket.Dialer{}, format.JSON)
+  err := receiver.StartReceiver(context.Background(), func(ctx context.Context, event cloudevents.Event) error { return nil })
+  var failure *CloudEventError
+  if !errors.As(err, &failure) || failure.Code != ErrReceiveFailed {
+    t.Fatalf("StartReceiver = %v, want a receive failure", err)
+  }
+
+  // Nothing is left waiting on a context that is never done
+  waitFor(t, "the receiver goroutines to exit", func() bool { return runtime.NumGoroutine() <= before })
+}